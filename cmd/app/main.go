@@ -1,10 +1,50 @@
 package main
 
-import "github.com/petrkoval/social-network-back/internal/app"
+import (
+	"flag"
+	"fmt"
+	"github.com/petrkoval/social-network-back/internal/app"
+	"github.com/petrkoval/social-network-back/internal/config"
+	"os"
+	"strings"
+)
+
+// configPaths collects the --config flag, which may be repeated.
+type configPaths []string
+
+func (p *configPaths) String() string {
+	return strings.Join(*p, ",")
+}
+
+func (p *configPaths) Set(path string) error {
+	*p = append(*p, path)
+	return nil
+}
 
 func main() {
-	sp := app.NewServiceProvider()
-	sp.Init()
+	var paths configPaths
+	flag.Var(&paths, "config", fmt.Sprintf(
+		"path to a YAML config file; may be repeated, later files override earlier ones (default %s)", config.DefaultPath,
+	))
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: app [--config path]... [migrate ... | config print]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	sp := app.NewServiceProvider(paths...)
+	args := flag.Args()
 
-	sp.StartServer()
+	switch {
+	case len(args) == 0:
+		sp.Init()
+		sp.StartServer()
+	case args[0] == "migrate":
+		sp.Migrate(args[1:])
+	case args[0] == "config":
+		sp.PrintConfig(args[1:])
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
 }
@@ -0,0 +1,35 @@
+package app
+
+import (
+	"fmt"
+	"github.com/petrkoval/social-network-back/internal/config"
+	"gopkg.in/yaml.v3"
+	"os"
+)
+
+// PrintConfig runs the config subcommand: "config print" writes the effective config,
+// with secrets redacted, to stdout as YAML and reports whether it is valid.
+func (sp *ServiceProvider) PrintConfig(args []string) {
+	sp.initLogger()
+
+	if len(args) != 1 || args[0] != "print" {
+		sp.logger.Fatal().Msg("usage: app config print")
+	}
+
+	cfg, err := config.Read(sp.configPaths...)
+	if err != nil {
+		sp.logger.Fatal().Err(err).Msg("failed to read config")
+	}
+
+	out, err := yaml.Marshal(cfg.Redacted())
+	if err != nil {
+		sp.logger.Fatal().Err(err).Msg("failed to encode config")
+	}
+	fmt.Print(string(out))
+
+	err = cfg.Validate()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,104 @@
+package app
+
+import (
+	"fmt"
+	"github.com/petrkoval/social-network-back/pkg/db/postgres"
+	"github.com/pkg/errors"
+	"strconv"
+)
+
+const migrateUsage = "usage: app migrate up | down [steps] | status | force <version>"
+
+// Migrate runs the migrate subcommand with the arguments that follow it:
+//
+//	up               apply all pending migrations
+//	down [steps]     revert the last steps migrations, one by default
+//	status           print the current and the latest version
+//	force <version>  set the version without running anything, after fixing a failed migration
+func (sp *ServiceProvider) Migrate(args []string) {
+	sp.initLogger()
+	sp.initConfig()
+	sp.initDbClient()
+	defer sp.dbClient.Close()
+
+	err := sp.migrate(args)
+	if err != nil {
+		sp.logger.Fatal().Err(err).Msg("migrate failed")
+	}
+}
+
+func (sp *ServiceProvider) migrate(args []string) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+
+	m, err := sp.newMigrator()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = m.Close()
+	}()
+
+	switch {
+	case args[0] == "up" && len(args) == 1:
+		return m.Up()
+	case args[0] == "down" && len(args) <= 2:
+		steps := 1
+		if len(args) == 2 {
+			steps, err = strconv.Atoi(args[1])
+			if err != nil || steps <= 0 {
+				return errors.Errorf("invalid number of steps %q", args[1])
+			}
+		}
+
+		return m.Down(steps)
+	case args[0] == "status" && len(args) == 1:
+		status, err := m.Status()
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("version: %d\nlatest: %d\ndirty: %t\n", status.Version, status.Latest, status.Dirty)
+		return nil
+	case args[0] == "force" && len(args) == 2:
+		version, err := strconv.Atoi(args[1])
+		if err != nil {
+			return errors.Errorf("invalid version %q", args[1])
+		}
+
+		return m.Force(version)
+	default:
+		return errors.New(migrateUsage)
+	}
+}
+
+// initMigrations applies the pending migrations when AutoMigrate is set. Either way,
+// readiness requires the database to be at least at the latest migration.
+func (sp *ServiceProvider) initMigrations() {
+	checker, err := postgres.NewMigrationChecker(sp.dbClient)
+	if err != nil {
+		sp.logger.Fatal().Err(err).Msg("failed to init migration checker")
+	}
+	sp.RegisterHealthChecker(checker)
+
+	if !sp.cfg.AutoMigrate {
+		return
+	}
+	sp.logger.Debug().Msg("applying database migrations")
+
+	m, err := sp.newMigrator()
+	if err == nil {
+		err = m.Up()
+		_ = m.Close()
+	}
+	if err != nil {
+		sp.logger.Fatal().Err(err).Msg("failed to apply database migrations")
+	}
+}
+
+func (sp *ServiceProvider) newMigrator() (*postgres.Migrator, error) {
+	sp.logger.Debug().Msg("creating migrator")
+
+	return postgres.NewMigrator(sp.dbClient, sp.logger)
+}
@@ -1,42 +1,130 @@
 package app
 
 import (
+	"context"
+	"errors"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/petrkoval/social-network-back/internal/config"
 	"github.com/petrkoval/social-network-back/internal/logger"
+	"github.com/petrkoval/social-network-back/internal/realtime"
 	"github.com/petrkoval/social-network-back/internal/services"
 	"github.com/petrkoval/social-network-back/internal/storage"
 	"github.com/petrkoval/social-network-back/internal/transport/http"
 	"github.com/petrkoval/social-network-back/internal/transport/http/handlers"
+	"github.com/petrkoval/social-network-back/pkg/blob"
 	"github.com/petrkoval/social-network-back/pkg/db/postgres"
 	"github.com/rs/zerolog"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
 )
 
 type ServiceProvider struct {
-	cfg            *config.Config
-	logger         *zerolog.Logger
-	dbClient       *pgxpool.Pool
-	router         *http.Router
-	authHandler    handlers.Handler
-	channelHandler handlers.Handler
+	configPaths         []string
+	cfg                 *config.Config
+	logger              *zerolog.Logger
+	dbClient            *pgxpool.Pool
+	healthCheckers      []handlers.HealthChecker
+	hub                 *realtime.Hub
+	mediaProcessor      *services.MediaProcessor
+	router              *http.Router
+	authHandler         handlers.Handler
+	channelHandler      handlers.Handler
+	postHandler         handlers.Handler
+	sessionHandler      handlers.Handler
+	userHandler         handlers.Handler
+	feedHandler         handlers.Handler
+	commentHandler      handlers.Handler
+	messageHandler      handlers.Handler
+	realtimeHandler     handlers.Handler
+	notificationHandler handlers.Handler
+	mediaHandler        handlers.Handler
+	searchHandler       handlers.Handler
+	hashtagHandler      handlers.Handler
 }
 
-func NewServiceProvider() *ServiceProvider {
-	return &ServiceProvider{}
+// NewServiceProvider takes the config files to load, in order; see config.Load.
+func NewServiceProvider(configPaths ...string) *ServiceProvider {
+	return &ServiceProvider{configPaths: configPaths}
 }
 
 func (sp *ServiceProvider) Init() {
 	sp.initLogger()
 	sp.initConfig()
 	sp.initDbClient()
+	sp.initMigrations()
+	sp.initHub()
 	sp.initRouter()
 	sp.initHandlers()
 }
 
+// StartServer serves until SIGINT or SIGTERM, or until the server fails, and then shuts
+// down: in-flight requests are drained and WebSocket clients are disconnected, both within
+// the shutdown timeout, then background workers are stopped and the database pool is closed.
 func (sp *ServiceProvider) StartServer() {
 	sp.logger.Debug().Msg("starting server")
 
-	sp.logger.Fatal().Err(sp.router.Start()).Msg("failed to start server")
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	workers, cancelWorkers := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+
+		err := sp.hub.Run(workers)
+		if !errors.Is(err, context.Canceled) {
+			sp.logger.Error().Err(err).Msg("realtime hub stopped")
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		sp.mediaProcessor.Run(workers)
+	}()
+
+	serverErr := make(chan error, 1)
+	go func() {
+		serverErr <- sp.router.Start()
+	}()
+
+	var failed error
+	select {
+	case <-ctx.Done():
+		sp.logger.Info().Msg("shutting down server")
+	case failed = <-serverErr:
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), sp.router.ShutdownTimeout())
+	defer cancel()
+
+	err := sp.router.Shutdown(shutdownCtx)
+	if err != nil {
+		sp.logger.Error().Err(err).Msg("failed to drain in-flight requests")
+	}
+
+	err = sp.hub.Close(shutdownCtx)
+	if err != nil {
+		sp.logger.Error().Err(err).Msg("failed to disconnect realtime clients")
+	}
+
+	cancelWorkers()
+	wg.Wait()
+
+	sp.dbClient.Close()
+
+	if failed != nil {
+		sp.logger.Fatal().Err(failed).Msg("failed to start server")
+	}
+	sp.logger.Info().Msg("server stopped")
+}
+
+// RegisterHealthChecker adds a dependency to the readiness probe. Checkers have to be
+// registered before the handlers are initialized.
+func (sp *ServiceProvider) RegisterHealthChecker(c handlers.HealthChecker) {
+	sp.healthCheckers = append(sp.healthCheckers, c)
 }
 
 func (sp *ServiceProvider) initLogger() {
@@ -51,7 +139,7 @@ func (sp *ServiceProvider) initConfig() {
 	sp.logger.Debug().Msg("initializing config")
 
 	if sp.cfg == nil {
-		sp.cfg, err = config.MustLoad()
+		sp.cfg, err = config.Load(sp.configPaths...)
 		if err != nil {
 			sp.logger.Fatal().Err(err).Msg("failed to init config")
 		}
@@ -63,10 +151,26 @@ func (sp *ServiceProvider) initDbClient() {
 	sp.logger.Debug().Msg("initializing db client")
 
 	if sp.dbClient == nil {
-		sp.dbClient, err = postgres.NewPostgreSQLClient(sp.cfg.Database, sp.logger)
+		sp.dbClient, err = postgres.NewPostgreSQLClient(&sp.cfg.Database, sp.logger)
 		if err != nil {
 			sp.logger.Fatal().Err(err).Msg("failed to init database client")
 		}
+
+		sp.RegisterHealthChecker(postgres.NewPoolChecker(sp.dbClient))
+	}
+}
+
+func (sp *ServiceProvider) initHub() {
+	sp.logger.Debug().Msg("initializing realtime hub")
+
+	if sp.hub == nil {
+		var broker realtime.Broker = realtime.NewLocalBroker()
+		if sp.cfg.Realtime.Broker == "postgres" {
+			broker = realtime.NewPostgresBroker(sp.dbClient, sp.logger)
+		}
+
+		subscriptionStorage := storage.NewSubscriptionStorage(sp.dbClient)
+		sp.hub = realtime.NewHub(broker, subscriptionStorage, sp.logger)
 	}
 }
 
@@ -74,7 +178,7 @@ func (sp *ServiceProvider) initRouter() {
 	sp.logger.Debug().Msg("initializing router")
 
 	if sp.router == nil {
-		sp.router = http.NewRouter(sp.cfg.Server)
+		sp.router = http.NewRouter(&sp.cfg.Server)
 		sp.router.InitMiddlewares()
 	}
 }
@@ -82,32 +186,88 @@ func (sp *ServiceProvider) initRouter() {
 func (sp *ServiceProvider) initHandlers() {
 	sp.logger.Debug().Msg("initializing handlers")
 
-	tokenService := sp.newTokenService()
-	authService := sp.newAuthService(tokenService)
+	sessionStorage := storage.NewSessionStorage(sp.dbClient)
+	sessionService := sp.newSessionService(sessionStorage)
+	tokenService := sp.newTokenService(sessionStorage)
+	mediaStorage := storage.NewMediaStorage(sp.dbClient)
+	mediaService, maxMediaSize := sp.newMediaService(mediaStorage)
+	userStorage := storage.NewUserStorage(sp.dbClient)
+	userService := sp.newUserService(userStorage, sessionService, mediaStorage)
+	authService := sp.newAuthService(tokenService, userService, sessionService)
 	channelService := sp.newChannelService()
+	subscriptionService := sp.newSubscriptionService()
+	notificationService := sp.newNotificationService()
+	followService := sp.newFollowService(notificationService)
+	feedService := sp.newFeedService()
+	postStorage := storage.NewPostStorage(sp.dbClient)
+	postService := sp.newPostService(postStorage, mediaStorage, userStorage, notificationService)
+	likeService := sp.newLikeService(postStorage, notificationService)
+	commentService := sp.newCommentService(postStorage, userStorage, notificationService)
+	hashtagService := sp.newHashtagService(postStorage)
+	messageService := sp.newMessageService()
+	searchService := sp.newSearchService()
 
 	authHandler := handlers.NewAuthHandler(authService, sp.logger)
-	channelHandler := handlers.NewChannelHandler(channelService, tokenService, sp.logger)
+	channelHandler := handlers.NewChannelHandler(channelService, subscriptionService, tokenService, sp.logger)
+	postHandler := handlers.NewPostHandler(postService, likeService, commentService, tokenService, sp.logger)
+	sessionHandler := handlers.NewSessionHandler(sessionService, tokenService, sp.logger)
+	userHandler := handlers.NewUserHandler(userService, followService, tokenService, sp.logger)
+	feedHandler := handlers.NewFeedHandler(feedService, tokenService, sp.logger)
+	commentHandler := handlers.NewCommentHandler(commentService, tokenService, sp.logger)
+	messageHandler := handlers.NewMessageHandler(messageService, tokenService, sp.logger)
+	realtimeHandler := handlers.NewRealtimeHandler(sp.hub, tokenService, sp.logger)
+	notificationHandler := handlers.NewNotificationHandler(notificationService, tokenService, sp.logger)
+	mediaHandler := handlers.NewMediaHandler(mediaService, maxMediaSize, tokenService, sp.logger)
+	searchHandler := handlers.NewSearchHandler(searchService, tokenService, sp.logger)
+	hashtagHandler := handlers.NewHashtagHandler(hashtagService, tokenService, sp.logger)
+	healthHandler := handlers.NewHealthHandler(sp.healthCheckers, sp.logger)
 
 	authHandler.MountOn(sp.router)
 	channelHandler.MountOn(sp.router)
+	postHandler.MountOn(sp.router)
+	sessionHandler.MountOn(sp.router)
+	userHandler.MountOn(sp.router)
+	feedHandler.MountOn(sp.router)
+	commentHandler.MountOn(sp.router)
+	messageHandler.MountOn(sp.router)
+	realtimeHandler.MountOn(sp.router)
+	notificationHandler.MountOn(sp.router)
+	mediaHandler.MountOn(sp.router)
+	searchHandler.MountOn(sp.router)
+	hashtagHandler.MountOn(sp.router)
+	healthHandler.MountOn(sp.router)
 }
 
-func (sp *ServiceProvider) newTokenService() *services.TokenService {
+func (sp *ServiceProvider) newTokenService(sessionStorage *storage.SessionStorage) *services.TokenService {
 	sp.logger.Debug().Msg("creating token service")
 
-	tokenStorage := storage.NewTokenStorage(sp.dbClient)
-	return services.NewTokenService(tokenStorage, sp.logger, sp.cfg.Tokens)
+	return services.NewTokenService(sp.logger, &sp.cfg.Tokens, sessionStorage)
 }
 
-func (sp *ServiceProvider) newAuthService(tokenService *services.TokenService) *services.AuthService {
-	sp.logger.Debug().Msg("creating auth service")
+func (sp *ServiceProvider) newSessionService(sessionStorage *storage.SessionStorage) *services.SessionService {
+	sp.logger.Debug().Msg("creating session service")
 
-	userStorage := storage.NewUserStorage(sp.dbClient)
+	return services.NewSessionService(sessionStorage, sp.logger)
+}
+
+func (sp *ServiceProvider) newUserService(
+	userStorage *storage.UserStorage,
+	sessionService *services.SessionService,
+	mediaStorage *storage.MediaStorage,
+) *services.UserService {
+	sp.logger.Debug().Msg("creating user service")
+
+	return services.NewUserService(userStorage, sessionService.Storage, mediaStorage, sp.logger)
+}
 
-	userService := services.NewUserService(userStorage, sp.logger)
+func (sp *ServiceProvider) newAuthService(
+	tokenService *services.TokenService,
+	userService *services.UserService,
+	sessionService *services.SessionService,
+) *services.AuthService {
+	sp.logger.Debug().Msg("creating auth service")
 
-	return services.NewAuthService(tokenService, userService)
+	return services.NewAuthService(tokenService, userService, sessionService)
 }
 
 func (sp *ServiceProvider) newChannelService() handlers.ChannelService {
@@ -115,5 +275,125 @@ func (sp *ServiceProvider) newChannelService() handlers.ChannelService {
 
 	s := storage.NewChannelStorage(sp.dbClient)
 
-	return services.NewChannelService(s, sp.logger, sp.cfg.Tokens)
+	return services.NewChannelService(s, sp.logger, &sp.cfg.Tokens)
+}
+
+func (sp *ServiceProvider) newPostService(
+	postStorage *storage.PostStorage,
+	mediaStorage *storage.MediaStorage,
+	userStorage *storage.UserStorage,
+	notificationService *services.NotificationService,
+) handlers.PostService {
+	sp.logger.Debug().Msg("creating post service")
+
+	channelStorage := storage.NewChannelStorage(sp.dbClient)
+
+	return services.NewPostService(
+		postStorage, channelStorage, mediaStorage, userStorage, sp.hub, notificationService, sp.logger,
+	)
+}
+
+func (sp *ServiceProvider) newLikeService(
+	postStorage *storage.PostStorage,
+	notificationService *services.NotificationService,
+) handlers.LikeService {
+	sp.logger.Debug().Msg("creating like service")
+
+	s := storage.NewLikeStorage(sp.dbClient)
+
+	return services.NewLikeService(s, postStorage, notificationService, sp.logger)
+}
+
+func (sp *ServiceProvider) newSubscriptionService() handlers.SubscriptionService {
+	sp.logger.Debug().Msg("creating subscription service")
+
+	s := storage.NewSubscriptionStorage(sp.dbClient)
+
+	return services.NewSubscriptionService(s, sp.logger)
+}
+
+func (sp *ServiceProvider) newFollowService(notificationService *services.NotificationService) handlers.FollowService {
+	sp.logger.Debug().Msg("creating follow service")
+
+	s := storage.NewFollowStorage(sp.dbClient)
+
+	return services.NewFollowService(s, sp.hub, notificationService, sp.logger)
+}
+
+func (sp *ServiceProvider) newFeedService() handlers.FeedService {
+	sp.logger.Debug().Msg("creating feed service")
+
+	timeline := storage.NewFeedStorage(sp.dbClient)
+
+	return services.NewFeedService(timeline, sp.logger)
+}
+
+func (sp *ServiceProvider) newCommentService(
+	postStorage *storage.PostStorage,
+	userStorage *storage.UserStorage,
+	notificationService *services.NotificationService,
+) handlers.CommentService {
+	sp.logger.Debug().Msg("creating comment service")
+
+	s := storage.NewCommentStorage(sp.dbClient)
+
+	return services.NewCommentService(s, postStorage, userStorage, sp.hub, notificationService, sp.logger)
+}
+
+func (sp *ServiceProvider) newHashtagService(postStorage *storage.PostStorage) handlers.HashtagService {
+	sp.logger.Debug().Msg("creating hashtag service")
+
+	s := storage.NewHashtagStorage(sp.dbClient)
+
+	return services.NewHashtagService(s, postStorage, sp.logger)
+}
+
+func (sp *ServiceProvider) newMessageService() handlers.MessageService {
+	sp.logger.Debug().Msg("creating message service")
+
+	s := storage.NewMessageStorage(sp.dbClient)
+	conversationStorage := storage.NewConversationStorage(sp.dbClient)
+
+	return services.NewMessageService(s, conversationStorage, sp.hub, sp.logger)
+}
+
+func (sp *ServiceProvider) newNotificationService() *services.NotificationService {
+	sp.logger.Debug().Msg("creating notification service")
+
+	s := storage.NewNotificationStorage(sp.dbClient)
+
+	return services.NewNotificationService(s, sp.hub, sp.logger)
+}
+
+func (sp *ServiceProvider) newSearchService() handlers.SearchService {
+	sp.logger.Debug().Msg("creating search service")
+
+	engine := storage.NewSearchStorage(sp.dbClient)
+
+	return services.NewSearchService(engine)
+}
+
+// newMediaService also returns the upload size limit, which the handler enforces on the request body.
+// The media processor it creates is kept on sp and started along with the server.
+func (sp *ServiceProvider) newMediaService(mediaStorage *storage.MediaStorage) (handlers.MediaService, int64) {
+	sp.logger.Debug().Msg("creating media service")
+
+	var (
+		cfg   = sp.cfg.Media
+		blobs services.BlobStore
+		err   error
+	)
+
+	if cfg.Storage == "s3" {
+		blobs, err = blob.NewS3Store(&cfg.S3)
+	} else {
+		blobs, err = blob.NewLocalStore(cfg.Path)
+	}
+	if err != nil {
+		sp.logger.Fatal().Err(err).Msg("failed to init blob store")
+	}
+
+	sp.mediaProcessor = services.NewMediaProcessor(mediaStorage, blobs, cfg.Workers, sp.logger)
+
+	return services.NewMediaService(mediaStorage, blobs, sp.mediaProcessor, cfg.MaxSize, sp.logger), cfg.MaxSize
 }
@@ -2,41 +2,174 @@ package config
 
 import (
 	"github.com/ilyakaznacheev/cleanenv"
+	"github.com/pkg/errors"
+	"os"
 )
 
+// DefaultPath is the config file read when no path is given. Unlike explicitly
+// given files, it may be missing, in which case the config comes from the environment.
+const DefaultPath = "config.yaml"
+
+// Config is the configuration of the application. AutoMigrate applies the pending
+// database migrations on startup.
+//
+// Every field can be overridden by the environment variable in its env tag, prefixed
+// with the env-prefix of the sections it is nested in, e.g. DB_PASSWORD or MEDIA_S3_BUCKET.
 type Config struct {
-	Server   *ServerConfig `yaml:"server"`
-	Database *DBConfig     `yaml:"database"`
-	Tokens   *TokensConfig `yaml:"tokens"`
+	Server      ServerConfig   `yaml:"server" env-prefix:"SERVER_"`
+	Database    DBConfig       `yaml:"database" env-prefix:"DB_"`
+	Tokens      TokensConfig   `yaml:"tokens" env-prefix:"TOKENS_"`
+	Realtime    RealtimeConfig `yaml:"realtime" env-prefix:"REALTIME_"`
+	Media       MediaConfig    `yaml:"media" env-prefix:"MEDIA_"`
+	AutoMigrate bool           `yaml:"auto_migrate" env:"AUTO_MIGRATE"`
 }
 
+// ServerConfig timeouts are in seconds, zero meaning no timeout. ShutdownTimeout bounds
+// how long in-flight requests are drained on shutdown.
 type ServerConfig struct {
-	Host         string `yaml:"host"`
-	Port         int    `yaml:"port"`
-	WriteTimeout int    `yaml:"write_timeout"`
-	ReadTimeout  int    `yaml:"read_timeout"`
+	Host              string `yaml:"host" env:"HOST"`
+	Port              int    `yaml:"port" env:"PORT"`
+	WriteTimeout      int    `yaml:"write_timeout" env:"WRITE_TIMEOUT"`
+	ReadTimeout       int    `yaml:"read_timeout" env:"READ_TIMEOUT"`
+	ReadHeaderTimeout int    `yaml:"read_header_timeout" env:"READ_HEADER_TIMEOUT"`
+	IdleTimeout       int    `yaml:"idle_timeout" env:"IDLE_TIMEOUT"`
+	ShutdownTimeout   int    `yaml:"shutdown_timeout" env:"SHUTDOWN_TIMEOUT"`
 }
 
 type DBConfig struct {
-	Host     string `yaml:"host"`
-	Port     int    `yaml:"port"`
-	User     string `yaml:"username"`
-	Password string `yaml:"password"`
-	Database string `yaml:"database"`
+	Host     string `yaml:"host" env:"HOST"`
+	Port     int    `yaml:"port" env:"PORT"`
+	User     string `yaml:"username" env:"USERNAME"`
+	Password string `yaml:"password" env:"PASSWORD"`
+	Database string `yaml:"database" env:"DATABASE"`
 }
 
 type TokensConfig struct {
-	AccessSecret  string `yaml:"access_secret"`
-	RefreshSecret string `yaml:"refresh_secret"`
+	AccessSecret  string `yaml:"access_secret" env:"ACCESS_SECRET"`
+	RefreshSecret string `yaml:"refresh_secret" env:"REFRESH_SECRET"`
 }
 
-func MustLoad() (*Config, error) {
-	cfg := new(Config)
+// RealtimeConfig selects the broker of realtime events: "local" for a single instance
+// or "postgres" to fan events out to all instances via LISTEN/NOTIFY.
+type RealtimeConfig struct {
+	Broker string `yaml:"broker" env:"BROKER"`
+}
 
-	err := cleanenv.ReadConfig("config.yaml", cfg)
+// MediaConfig selects where uploaded files are kept: "local" stores them under Path,
+// "s3" in the bucket described by S3. MaxSize is the upload limit in bytes and
+// Workers the number of images processed at once.
+type MediaConfig struct {
+	Storage string   `yaml:"storage" env:"STORAGE"`
+	Path    string   `yaml:"path" env:"PATH"`
+	MaxSize int64    `yaml:"max_size" env:"MAX_SIZE"`
+	Workers int      `yaml:"workers" env:"WORKERS"`
+	S3      S3Config `yaml:"s3" env-prefix:"S3_"`
+}
+
+type S3Config struct {
+	Endpoint  string `yaml:"endpoint" env:"ENDPOINT"`
+	Region    string `yaml:"region" env:"REGION"`
+	Bucket    string `yaml:"bucket" env:"BUCKET"`
+	AccessKey string `yaml:"access_key" env:"ACCESS_KEY"`
+	SecretKey string `yaml:"secret_key" env:"SECRET_KEY"`
+	UseSSL    bool   `yaml:"use_ssl" env:"USE_SSL"`
+}
+
+// Load reads the config like Read and validates it.
+func Load(paths ...string) (*Config, error) {
+	cfg, err := Read(paths...)
 	if err != nil {
 		return nil, err
 	}
 
+	err = cfg.Validate()
+	if err != nil {
+		return nil, errors.Wrap(err, "config.Load")
+	}
+
 	return cfg, nil
 }
+
+// Read starts from the defaults, then reads the given YAML files in order, each
+// overriding the fields set by the ones before it, and finally applies the environment.
+// With no paths, DefaultPath is read if it exists.
+func Read(paths ...string) (*Config, error) {
+	cfg := defaults()
+
+	if len(paths) == 0 {
+		_, err := os.Stat(DefaultPath)
+		if err == nil {
+			paths = []string{DefaultPath}
+		}
+	}
+
+	for _, path := range paths {
+		err := parseFile(path, cfg)
+		if err != nil {
+			return nil, errors.Wrap(err, "config.Read")
+		}
+	}
+
+	err := cleanenv.ReadEnv(cfg)
+	if err != nil {
+		return nil, errors.Wrap(err, "config.Read")
+	}
+
+	return cfg, nil
+}
+
+// defaults are filled in before anything is read rather than by cleanenv, which would
+// also override fields explicitly set to zero, e.g. a timeout turned off.
+func defaults() *Config {
+	return &Config{
+		Server: ServerConfig{
+			Port:              8080,
+			WriteTimeout:      30,
+			ReadTimeout:       15,
+			ReadHeaderTimeout: 5,
+			IdleTimeout:       60,
+			ShutdownTimeout:   15,
+		},
+		Database: DBConfig{
+			Host: "localhost",
+			Port: 5432,
+		},
+		Realtime: RealtimeConfig{
+			Broker: "local",
+		},
+		Media: MediaConfig{
+			Storage: "local",
+			Path:    "uploads",
+			MaxSize: 10 << 20,
+			Workers: 2,
+		},
+	}
+}
+
+func parseFile(path string, cfg *Config) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return errors.Wrap(cleanenv.ParseYAML(f, cfg), path)
+}
+
+// Redacted returns a copy of the config with the secrets replaced, safe to print or log.
+func (c *Config) Redacted() *Config {
+	redacted := *c
+
+	redact(&redacted.Database.Password)
+	redact(&redacted.Tokens.AccessSecret)
+	redact(&redacted.Tokens.RefreshSecret)
+	redact(&redacted.Media.S3.SecretKey)
+
+	return &redacted
+}
+
+func redact(secret *string) {
+	if *secret != "" {
+		*secret = "[REDACTED]"
+	}
+}
@@ -0,0 +1,74 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	err := os.WriteFile(path, []byte(content), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func TestReadDefaults(t *testing.T) {
+	cfg, err := Read(writeConfig(t, "server:\n  port: 9000\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.Server.Port != 9000 {
+		t.Errorf("port is %d, want 9000", cfg.Server.Port)
+	}
+	if cfg.Server.WriteTimeout != 30 || cfg.Server.ReadTimeout != 15 || cfg.Server.ShutdownTimeout != 15 {
+		t.Errorf("timeouts are not defaulted: %+v", cfg.Server)
+	}
+	if cfg.Database.Host != "localhost" || cfg.Media.Storage != "local" || cfg.Media.MaxSize != 10<<20 {
+		t.Errorf("defaults are missing: %+v, %+v", cfg.Database, cfg.Media)
+	}
+}
+
+func TestReadExplicitZero(t *testing.T) {
+	t.Setenv("SERVER_IDLE_TIMEOUT", "0")
+
+	cfg, err := Read(writeConfig(t, "server:\n  write_timeout: 0\n  read_timeout: 0\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.Server.WriteTimeout != 0 || cfg.Server.ReadTimeout != 0 || cfg.Server.IdleTimeout != 0 {
+		t.Errorf("explicit zero timeouts were replaced: %+v", cfg.Server)
+	}
+	if cfg.Server.ReadHeaderTimeout != 5 {
+		t.Errorf("read header timeout is %d, want the default 5", cfg.Server.ReadHeaderTimeout)
+	}
+}
+
+func TestReadOverrides(t *testing.T) {
+	t.Setenv("SERVER_PORT", "7000")
+
+	base := writeConfig(t, "server:\n  port: 9000\n  write_timeout: 60\ndatabase:\n  host: db\n")
+	local := writeConfig(t, "server:\n  write_timeout: 90\n")
+
+	cfg, err := Read(base, local)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.Server.Port != 7000 {
+		t.Errorf("port is %d, want the environment's 7000", cfg.Server.Port)
+	}
+	if cfg.Server.WriteTimeout != 90 {
+		t.Errorf("write timeout is %d, want the last file's 90", cfg.Server.WriteTimeout)
+	}
+	if cfg.Database.Host != "db" {
+		t.Errorf("database host is %q, want the first file's db", cfg.Database.Host)
+	}
+}
@@ -0,0 +1,56 @@
+package config
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"strings"
+)
+
+// minSecretLength is the size of the HMAC-SHA256 key that signs the tokens.
+const minSecretLength = 32
+
+// Validate reports every invalid field at once rather than just the first one.
+func (c *Config) Validate() error {
+	var problems []string
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			problems = append(problems, fmt.Sprintf(format, args...))
+		}
+	}
+
+	check(c.Server.Port > 0 && c.Server.Port <= 65535, "server.port must be between 1 and 65535")
+	check(c.Server.WriteTimeout >= 0, "server.write_timeout must not be negative")
+	check(c.Server.ReadTimeout >= 0, "server.read_timeout must not be negative")
+	check(c.Server.ReadHeaderTimeout >= 0, "server.read_header_timeout must not be negative")
+	check(c.Server.IdleTimeout >= 0, "server.idle_timeout must not be negative")
+	check(c.Server.ShutdownTimeout > 0, "server.shutdown_timeout must be positive")
+
+	check(c.Database.Host != "", "database.host is required")
+	check(c.Database.Port > 0 && c.Database.Port <= 65535, "database.port must be between 1 and 65535")
+	check(c.Database.User != "", "database.username is required")
+	check(c.Database.Database != "", "database.database is required")
+
+	check(len(c.Tokens.AccessSecret) >= minSecretLength, "tokens.access_secret must be at least %d bytes", minSecretLength)
+	check(len(c.Tokens.RefreshSecret) >= minSecretLength, "tokens.refresh_secret must be at least %d bytes", minSecretLength)
+	check(c.Tokens.AccessSecret != c.Tokens.RefreshSecret, "tokens.access_secret and tokens.refresh_secret must differ")
+
+	check(c.Realtime.Broker == "local" || c.Realtime.Broker == "postgres", `realtime.broker must be "local" or "postgres"`)
+
+	check(c.Media.MaxSize > 0, "media.max_size must be positive")
+	check(c.Media.Workers > 0, "media.workers must be positive")
+	switch c.Media.Storage {
+	case "local":
+		check(c.Media.Path != "", "media.path is required for local storage")
+	case "s3":
+		check(c.Media.S3.Endpoint != "", "media.s3.endpoint is required for s3 storage")
+		check(c.Media.S3.Bucket != "", "media.s3.bucket is required for s3 storage")
+	default:
+		check(false, `media.storage must be "local" or "s3"`)
+	}
+
+	if len(problems) > 0 {
+		return errors.Errorf("invalid config: %s", strings.Join(problems, "; "))
+	}
+
+	return nil
+}
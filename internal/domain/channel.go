@@ -3,20 +3,21 @@ package domain
 import "time"
 
 type Channel struct {
-	ID          string    `json:"id" db:"channel_id"`
-	UserID      string    `json:"user_id" db:"user_id"`
-	CreatedAt   time.Time `json:"created_at" db:"created_at"`
-	Title       string    `json:"title" db:"title"`
-	Description string    `json:"description" db:"description"`
+	ID              string    `json:"id" db:"channel_id"`
+	UserID          string    `json:"user_id" db:"user_id"`
+	CreatedAt       time.Time `json:"created_at" db:"created_at"`
+	Title           string    `json:"title" db:"title"`
+	Description     string    `json:"description" db:"description"`
+	SubscriberCount int       `json:"subscriber_count" db:"subscriber_count"`
 }
 
 type CreateChannelDTO struct {
 	UserID      string `json:"user_id" db:"user_id"`
-	Title       string `json:"title" db:"title"`
-	Description string `json:"description" db:"description"`
+	Title       string `json:"title" db:"title" validate:"required,max=32"`
+	Description string `json:"description" db:"description" validate:"max=256"`
 }
 
 type UpdateChannelDTO struct {
-	Title       string `json:"title" db:"title"`
-	Description string `json:"description" db:"description"`
+	Title       string `json:"title" db:"title" validate:"required,max=32"`
+	Description string `json:"description" db:"description" validate:"max=256"`
 }
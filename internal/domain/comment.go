@@ -0,0 +1,31 @@
+package domain
+
+import "time"
+
+// Comment is a comment on a post, optionally replying to another comment.
+// Deleted comments keep their place in the thread but lose their content.
+type Comment struct {
+	ID        string        `json:"id"                db:"comment_id"`
+	PostID    string        `json:"post_id"           db:"post_id"`
+	UserID    string        `json:"user_id"           db:"user_id"`
+	ParentID  *string       `json:"parent_comment_id" db:"parent_comment_id"`
+	Content   string        `json:"content"           db:"content"`
+	Entities  []*TextEntity `json:"entities"          db:"entities"`
+	CreatedAt time.Time     `json:"created_at"        db:"created_at"`
+	UpdatedAt *time.Time    `json:"updated_at"        db:"updated_at"`
+	Deleted   bool          `json:"deleted"           db:"deleted"`
+}
+
+type CreateCommentDTO struct {
+	PostID   string  `json:"-"                 db:"post_id"`
+	UserID   string  `json:"-"                 db:"user_id"`
+	ParentID *string `json:"parent_comment_id" db:"parent_comment_id" validate:"uuid"`
+	Content  string  `json:"content"           db:"content"           validate:"required,max=2000"`
+	// Entities are parsed from Content by the service.
+	Entities []*TextEntity `json:"-" db:"entities"`
+}
+
+type UpdateCommentDTO struct {
+	Content  string        `json:"content" db:"content" validate:"required,max=2000"`
+	Entities []*TextEntity `json:"-" db:"entities"`
+}
@@ -0,0 +1,23 @@
+package domain
+
+const (
+	EntityHashtag = "hashtag"
+	EntityMention = "mention"
+)
+
+// TextEntity marks a hashtag or a mention in a text so that clients can render it as a link.
+// Offset and Length count Unicode code points and include the leading '#' or '@'.
+// Value is the lowercased hashtag or the mentioned username, both without the prefix.
+type TextEntity struct {
+	Type   string `json:"type"`
+	Offset int    `json:"offset"`
+	Length int    `json:"length"`
+	Value  string `json:"value"`
+	UserID string `json:"user_id,omitempty"`
+}
+
+// Hashtag is a hashtag together with the number of posts using it within a time window.
+type Hashtag struct {
+	Name      string `json:"name"       db:"name"`
+	PostCount int    `json:"post_count" db:"post_count"`
+}
@@ -0,0 +1,18 @@
+package domain
+
+const (
+	EventMessageCreated      = "message.created"
+	EventCommentCreated      = "comment.created"
+	EventFollowerCreated     = "follower.created"
+	EventPostCreated         = "post.created"
+	EventNotificationCreated = "notification.created"
+)
+
+// Event is pushed to connected clients. It is delivered to UserIDs and, when ChannelID
+// is set, to every subscriber of the channel.
+type Event struct {
+	Type      string   `json:"type"`
+	Payload   any      `json:"payload"`
+	UserIDs   []string `json:"-"`
+	ChannelID string   `json:"-"`
+}
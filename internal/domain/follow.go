@@ -0,0 +1,13 @@
+package domain
+
+import "time"
+
+type Follower struct {
+	User
+	FollowedAt time.Time `json:"followed_at" db:"followed_at"`
+}
+
+type Subscriber struct {
+	User
+	SubscribedAt time.Time `json:"subscribed_at" db:"subscribed_at"`
+}
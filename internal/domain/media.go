@@ -0,0 +1,83 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	MediaPending = "pending"
+	MediaReady   = "ready"
+	MediaFailed  = "failed"
+)
+
+// Media is an uploaded file. Its content is stored once per ContentHash, however
+// many times it was uploaded. The original is never served: once processed, the
+// media is available as variants stripped of metadata.
+type Media struct {
+	ID          string    `json:"id"           db:"media_id"`
+	UserID      string    `json:"user_id"      db:"user_id"`
+	ContentHash string    `json:"-"            db:"content_hash"`
+	ContentType string    `json:"content_type" db:"content_type"`
+	Size        int64     `json:"size"         db:"size"`
+	Status      string    `json:"status"       db:"status"`
+	Width       *int      `json:"width"        db:"width"`
+	Height      *int      `json:"height"       db:"height"`
+	Blurhash    *string   `json:"blurhash"     db:"blurhash"`
+	CreatedAt   time.Time `json:"created_at"   db:"created_at"`
+}
+
+type CreateMediaDTO struct {
+	UserID      string `db:"user_id"`
+	ContentHash string `db:"content_hash"`
+	ContentType string `db:"content_type"`
+	Size        int64  `db:"size"`
+}
+
+type MediaVariant struct {
+	MediaID     string `json:"-"            db:"media_id"`
+	Name        string `json:"name"         db:"name"`
+	ContentHash string `json:"-"            db:"content_hash"`
+	ContentType string `json:"content_type" db:"content_type"`
+	Size        int64  `json:"size"         db:"size"`
+	Width       int    `json:"width"        db:"width"`
+	Height      int    `json:"height"       db:"height"`
+}
+
+// ProcessedMediaDTO is the outcome of processing an uploaded image.
+type ProcessedMediaDTO struct {
+	Width    int
+	Height   int
+	Blurhash string
+	Variants []*MediaVariant
+}
+
+// PostMedia is a media attached to a post. Variants holds the names of the ready
+// variants and is rendered as a map from variant name to its URL.
+type PostMedia struct {
+	ID       string   `json:"id"`
+	Width    *int     `json:"width"`
+	Height   *int     `json:"height"`
+	Blurhash *string  `json:"blurhash"`
+	Variants []string `json:"variants"`
+}
+
+func (m PostMedia) MarshalJSON() ([]byte, error) {
+	urls := make(map[string]string, len(m.Variants))
+	for _, name := range m.Variants {
+		urls[name] = MediaVariantURL(m.ID, name)
+	}
+
+	return json.Marshal(struct {
+		ID       string            `json:"id"`
+		Width    *int              `json:"width"`
+		Height   *int              `json:"height"`
+		Blurhash *string           `json:"blurhash"`
+		Variants map[string]string `json:"variants"`
+	}{m.ID, m.Width, m.Height, m.Blurhash, urls})
+}
+
+// MediaVariantURL is the path the variant of a media is served from.
+func MediaVariantURL(mediaID, variant string) string {
+	return "/media/" + mediaID + "?variant=" + variant
+}
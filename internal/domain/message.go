@@ -0,0 +1,42 @@
+package domain
+
+import "time"
+
+// Conversation is a private 1:1 or group conversation as seen by one of its members.
+// UnreadCount counts messages of other members after the viewer's last read message.
+type Conversation struct {
+	ID            string                `json:"id"              db:"conversation_id"`
+	CreatedAt     time.Time             `json:"created_at"      db:"created_at"`
+	LastMessageAt *time.Time            `json:"last_message_at" db:"last_message_at"`
+	Members       []*ConversationMember `json:"members"         db:"members"`
+	UnreadCount   int                   `json:"unread_count"    db:"unread_count"`
+}
+
+// ConversationMember carries the read receipt of a member.
+type ConversationMember struct {
+	UserID            string  `json:"user_id"`
+	LastReadMessageID *string `json:"last_read_message_id"`
+}
+
+type Message struct {
+	ID             string    `json:"id"              db:"message_id"`
+	ConversationID string    `json:"conversation_id" db:"conversation_id"`
+	UserID         string    `json:"user_id"         db:"user_id"`
+	Content        string    `json:"content"         db:"content"`
+	CreatedAt      time.Time `json:"created_at"      db:"created_at"`
+}
+
+type CreateConversationDTO struct {
+	MemberIDs []string `json:"member_ids" validate:"required,uuid"`
+}
+
+type SendMessageDTO struct {
+	ConversationID string `json:"-"       db:"conversation_id"`
+	UserID         string `json:"-"       db:"user_id"`
+	Content        string `json:"content" db:"content" validate:"required,max=4000"`
+}
+
+// MarkReadDTO moves the read receipt to MessageID, or to the latest message when it is empty.
+type MarkReadDTO struct {
+	MessageID string `json:"message_id" validate:"uuid"`
+}
@@ -0,0 +1,45 @@
+package domain
+
+import "time"
+
+const (
+	NotificationFollow  = "follow"
+	NotificationLike    = "like"
+	NotificationComment = "comment"
+	NotificationMention = "mention"
+)
+
+// Notification groups notifications of the same type about the same subject, e.g.
+// every like of a post: "X and 4 others liked your post". Actors holds the most
+// recent actors, ActorCount all of them.
+type Notification struct {
+	Key        string               `json:"key"         db:"key"`
+	Type       string               `json:"type"        db:"type"`
+	SubjectID  string               `json:"subject_id"  db:"subject_id"`
+	Actors     []*NotificationActor `json:"actors"      db:"actors"`
+	ActorCount int                  `json:"actor_count" db:"actor_count"`
+	Unread     bool                 `json:"unread"      db:"unread"`
+	CreatedAt  time.Time            `json:"created_at"  db:"created_at"`
+	LatestID   string               `json:"-"           db:"latest_id"`
+}
+
+type NotificationActor struct {
+	ID       string `json:"id"`
+	Username string `json:"username"`
+}
+
+// CreateNotificationDTO tells UserID that ActorID did something of Type to SubjectID:
+// the followed user for follows, the post for likes, comments and mentions.
+type CreateNotificationDTO struct {
+	UserID    string
+	ActorID   string
+	Type      string
+	SubjectID string
+}
+
+// MarkNotificationsReadDTO marks the groups with the given keys as read, or all of them
+// when All is set. Exactly one of them must be given.
+type MarkNotificationsReadDTO struct {
+	Keys []string `json:"keys" validate:"max=100"`
+	All  bool     `json:"all"`
+}
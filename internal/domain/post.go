@@ -0,0 +1,32 @@
+package domain
+
+import "time"
+
+type Post struct {
+	ID           string        `json:"id"         db:"post_id"`
+	UserID       string        `json:"user_id"    db:"user_id"`
+	ChannelID    *string       `json:"channel_id" db:"channel_id"`
+	CreatedAt    time.Time     `json:"created_at" db:"created_at"`
+	Content      string        `json:"content"    db:"content"`
+	Entities     []*TextEntity `json:"entities"   db:"entities"`
+	MediaIDs     []string      `json:"media_ids"  db:"media_ids"`
+	Media        []*PostMedia  `json:"media"      db:"media"`
+	LikeCount    int           `json:"like_count" db:"like_count"`
+	LikedByMe    bool          `json:"liked_by_me" db:"liked_by_me"`
+	CommentCount int           `json:"comment_count" db:"comment_count"`
+}
+
+type CreatePostDTO struct {
+	UserID    string   `json:"-"          db:"user_id"`
+	ChannelID *string  `json:"channel_id" db:"channel_id" validate:"uuid"`
+	Content   string   `json:"content"    db:"content"    validate:"max=5000"`
+	MediaIDs  []string `json:"media_ids"  db:"media_ids"  validate:"max=10,uuid"`
+	// Entities are parsed from Content by the service.
+	Entities []*TextEntity `json:"-" db:"entities"`
+}
+
+type UpdatePostDTO struct {
+	Content  string        `json:"content" db:"content" validate:"max=5000"`
+	MediaIDs []string      `json:"media_ids" db:"media_ids" validate:"max=10,uuid"`
+	Entities []*TextEntity `json:"-" db:"entities"`
+}
@@ -0,0 +1,15 @@
+package domain
+
+const (
+	SearchUsers    = "users"
+	SearchChannels = "channels"
+	SearchPosts    = "posts"
+)
+
+// SearchResults holds the best matches of each searched type, most relevant first.
+// Types that were not searched are null.
+type SearchResults struct {
+	Users    []*User    `json:"users"`
+	Channels []*Channel `json:"channels"`
+	Posts    []*Post    `json:"posts"`
+}
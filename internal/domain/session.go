@@ -0,0 +1,39 @@
+package domain
+
+import (
+	"github.com/golang-jwt/jwt/v5"
+	"time"
+)
+
+type Session struct {
+	ID         string    `json:"id"           db:"session_id"`
+	UserID     string    `json:"-"            db:"user_id"`
+	UserAgent  string    `json:"user_agent"   db:"user_agent"`
+	IP         string    `json:"ip"           db:"ip"`
+	CreatedAt  time.Time `json:"created_at"   db:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at" db:"last_used_at"`
+	ExpiresAt  time.Time `json:"expires_at"   db:"expires_at"`
+	Current    bool      `json:"current"      db:"-"`
+}
+
+// RefreshToken is a stored refresh token. Only its hash is kept; tokens of one
+// session form a family, and every token but the latest one has been rotated.
+type RefreshToken struct {
+	Hash      string     `db:"token_hash"`
+	FamilyID  string     `db:"family_id"`
+	UserID    string     `db:"user_id"`
+	CreatedAt time.Time  `db:"created_at"`
+	RotatedAt *time.Time `db:"rotated_at"`
+}
+
+// ClientInfo describes the device a session is opened from.
+type ClientInfo struct {
+	UserAgent string
+	IP        string
+}
+
+type TokenClaims struct {
+	Username  string `json:"username"`
+	SessionID string `json:"sid"`
+	jwt.RegisteredClaims
+}
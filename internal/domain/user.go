@@ -8,14 +8,37 @@ type User struct {
 	Password           string    `json:"-" db:"password"`
 	CreatedAt          time.Time `json:"created_at" db:"created_at"`
 	AccountDescription string    `json:"account_description" db:"account_description"`
+	AvatarID           *string   `json:"avatar_id" db:"avatar_id"`
+	FollowerCount      int       `json:"follower_count" db:"follower_count"`
+	FollowingCount     int       `json:"following_count" db:"following_count"`
 }
 
+// Passwords are limited to 72 bytes, which is as much as bcrypt hashes: a password of
+// fewer characters may still be longer in UTF-8.
 type CreateUserDTO struct {
-	Username string `json:"username" db:"username"`
-	Password string `json:"password" db:"password"`
+	Username string `json:"username" db:"username" validate:"required,max=16,username"`
+	Password string `json:"password" db:"password" validate:"required,min=8,maxbytes=72"`
+}
+
+type LoginDTO struct {
+	Username string `json:"username" validate:"required"`
+	Password string `json:"password" validate:"required"`
+}
+
+type UpdateUserDTO struct {
+	Username           *string `json:"username"            validate:"min=1,max=16,username"`
+	AccountDescription *string `json:"account_description" validate:"max=256"`
+	// AvatarID sets the avatar to an uploaded media; an empty string removes it.
+	AvatarID *string `json:"avatar_id" validate:"uuid"`
+}
+
+type ChangePasswordDTO struct {
+	CurrentPassword string `json:"current_password" validate:"required"`
+	NewPassword     string `json:"new_password"     validate:"required,min=8,maxbytes=72"`
 }
 
 type AuthUser struct {
-	ID       string `json:"id"       db:"user_id"`
-	Username string `json:"username" db:"username"`
+	ID        string `json:"id"       db:"user_id"`
+	Username  string `json:"username" db:"username"`
+	SessionID string `json:"-"        db:"-"`
 }
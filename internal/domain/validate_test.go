@@ -0,0 +1,60 @@
+package domain
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/petrkoval/social-network-back/pkg/validation"
+)
+
+// TestValidateTags checks the validate tags of every struct in the package, so that a
+// mistyped rule fails here rather than on the first request that uses it.
+func TestValidateTags(t *testing.T) {
+	files, err := filepath.Glob("*.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fset := token.NewFileSet()
+	checked := 0
+
+	for _, file := range files {
+		f, err := parser.ParseFile(fset, file, nil, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		ast.Inspect(f, func(n ast.Node) bool {
+			field, ok := n.(*ast.Field)
+			if !ok || field.Tag == nil {
+				return true
+			}
+
+			raw, err := strconv.Unquote(field.Tag.Value)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			tag, ok := reflect.StructTag(raw).Lookup("validate")
+			if !ok {
+				return true
+			}
+
+			checked++
+			if err := validation.CheckTag(tag); err != nil {
+				t.Errorf("%s: %v", fset.Position(field.Pos()), err)
+			}
+
+			return true
+		})
+	}
+
+	if checked == 0 {
+		t.Fatal("no validate tags found")
+	}
+}
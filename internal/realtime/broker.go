@@ -0,0 +1,43 @@
+package realtime
+
+import (
+	"context"
+	"github.com/petrkoval/social-network-back/internal/domain"
+)
+
+// Broker carries events between the instances of the application. Publish may be called
+// on any instance, Run delivers every published event to the local hub of each instance.
+type Broker interface {
+	Publish(ctx context.Context, event domain.Event) error
+	Run(ctx context.Context, deliver func(domain.Event)) error
+}
+
+// LocalBroker delivers events within the current process only. It is enough when a
+// single instance of the application is running.
+type LocalBroker struct {
+	events chan domain.Event
+}
+
+func NewLocalBroker() *LocalBroker {
+	return &LocalBroker{events: make(chan domain.Event, 256)}
+}
+
+func (b *LocalBroker) Publish(ctx context.Context, event domain.Event) error {
+	select {
+	case b.events <- event:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (b *LocalBroker) Run(ctx context.Context, deliver func(domain.Event)) error {
+	for {
+		select {
+		case event := <-b.events:
+			deliver(event)
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
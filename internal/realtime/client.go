@@ -0,0 +1,131 @@
+package realtime
+
+import (
+	"github.com/gorilla/websocket"
+	"github.com/rs/zerolog"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	writeWait      = 10 * time.Second
+	pongWait       = 60 * time.Second
+	pingPeriod     = pongWait * 9 / 10
+	maxMessageSize = 512
+	sendBufferSize = 64
+)
+
+// Client is a single WebSocket connection of a user. Events are queued into a bounded
+// buffer; a client that cannot keep up is disconnected instead of blocking the hub.
+type Client struct {
+	hub    *Hub
+	conn   *websocket.Conn
+	userID string
+	logger *zerolog.Logger
+
+	send      chan []byte
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// Serve upgrades the request to a WebSocket connection of userID and starts pumping
+// events to it. It returns as soon as the connection is set up, or ClosedHubErr once
+// the hub is closed.
+func (h *Hub) Serve(w http.ResponseWriter, r *http.Request, upgrader *websocket.Upgrader, userID string) error {
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return err
+	}
+
+	c := &Client{
+		hub:    h,
+		conn:   conn,
+		userID: userID,
+		logger: h.logger,
+		send:   make(chan []byte, sendBufferSize),
+		done:   make(chan struct{}),
+	}
+
+	if !h.register(c) {
+		_ = conn.Close()
+		return ClosedHubErr
+	}
+
+	go c.writePump()
+	go c.readPump()
+
+	return nil
+}
+
+func (c *Client) enqueue(message []byte) {
+	select {
+	case <-c.done:
+		return
+	case c.send <- message:
+	default:
+		c.logger.Warn().Str("user_id", c.userID).Msg("realtime client is too slow, disconnecting")
+		c.close()
+	}
+}
+
+func (c *Client) close() {
+	c.closeOnce.Do(func() {
+		close(c.done)
+	})
+}
+
+// readPump discards incoming messages; it is needed to process pongs and to notice
+// when the peer goes away.
+func (c *Client) readPump() {
+	defer func() {
+		c.hub.unregister(c)
+		c.close()
+		c.hub.pumps.Done()
+	}()
+
+	c.conn.SetReadLimit(maxMessageSize)
+	_ = c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	c.conn.SetPongHandler(func(string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
+
+	for {
+		_, _, err := c.conn.ReadMessage()
+		if err != nil {
+			return
+		}
+	}
+}
+
+func (c *Client) writePump() {
+	ticker := time.NewTicker(pingPeriod)
+	defer func() {
+		ticker.Stop()
+		_ = c.conn.Close()
+		c.hub.pumps.Done()
+	}()
+
+	for {
+		select {
+		case <-c.done:
+			_ = c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			_ = c.conn.WriteMessage(websocket.CloseMessage, []byte{})
+			return
+		case message := <-c.send:
+			_ = c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+
+			err := c.conn.WriteMessage(websocket.TextMessage, message)
+			if err != nil {
+				return
+			}
+		case <-ticker.C:
+			_ = c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+
+			err := c.conn.WriteMessage(websocket.PingMessage, nil)
+			if err != nil {
+				return
+			}
+		}
+	}
+}
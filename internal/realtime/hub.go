@@ -0,0 +1,162 @@
+package realtime
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/petrkoval/social-network-back/internal/domain"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+	"sync"
+	"time"
+)
+
+const audienceTimeout = 5 * time.Second
+
+var ClosedHubErr = errors.New("realtime hub is closed")
+
+// ChannelAudience narrows a list of users down to the subscribers of a channel.
+type ChannelAudience interface {
+	FilterSubscribers(ctx context.Context, channelID string, userIDs []string) ([]string, error)
+}
+
+// Hub keeps the WebSocket clients connected to this instance and delivers the events
+// coming from the broker to them.
+type Hub struct {
+	broker   Broker
+	audience ChannelAudience
+	logger   *zerolog.Logger
+
+	mu      sync.RWMutex
+	clients map[string]map[*Client]struct{}
+	closed  bool
+
+	// pumps tracks the goroutines of the clients, so that Close can wait for them.
+	pumps sync.WaitGroup
+}
+
+func NewHub(b Broker, a ChannelAudience, l *zerolog.Logger) *Hub {
+	return &Hub{
+		broker:   b,
+		audience: a,
+		logger:   l,
+		clients:  make(map[string]map[*Client]struct{}),
+	}
+}
+
+// Publish hands the event to the broker, which delivers it to the hubs of all instances.
+func (h *Hub) Publish(ctx context.Context, event domain.Event) error {
+	return h.broker.Publish(ctx, event)
+}
+
+// Run delivers events from the broker until ctx is done.
+func (h *Hub) Run(ctx context.Context) error {
+	return h.broker.Run(ctx, h.deliver)
+}
+
+// Close disconnects every client connected to this instance and refuses new ones.
+// Each client sends a close frame and drops its connection; Close waits for them until
+// ctx is done.
+func (h *Hub) Close(ctx context.Context) error {
+	h.mu.Lock()
+	h.closed = true
+	for _, clients := range h.clients {
+		for c := range clients {
+			c.close()
+		}
+	}
+	h.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		h.pumps.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return errors.Wrap(ctx.Err(), "Hub.Close")
+	}
+}
+
+// register adds the client and accounts for its two pumps, unless the hub is closed.
+func (h *Hub) register(c *Client) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed {
+		return false
+	}
+
+	if h.clients[c.userID] == nil {
+		h.clients[c.userID] = make(map[*Client]struct{})
+	}
+	h.clients[c.userID][c] = struct{}{}
+	h.pumps.Add(2)
+
+	return true
+}
+
+func (h *Hub) unregister(c *Client) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	delete(h.clients[c.userID], c)
+	if len(h.clients[c.userID]) == 0 {
+		delete(h.clients, c.userID)
+	}
+}
+
+func (h *Hub) deliver(event domain.Event) {
+	message, err := json.Marshal(event)
+	if err != nil {
+		h.logger.Error().Err(err).Str("type", event.Type).Msg("failed to encode realtime event")
+		return
+	}
+
+	recipients, err := h.recipients(event)
+	if err != nil {
+		h.logger.Error().Stack().Err(err).Str("type", event.Type).Msg("failed to resolve realtime event recipients")
+	}
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for _, userID := range recipients {
+		for c := range h.clients[userID] {
+			c.enqueue(message)
+		}
+	}
+}
+
+// recipients returns the users connected to this instance who should receive the event.
+func (h *Hub) recipients(event domain.Event) ([]string, error) {
+	// A copy, so that appending the subscribers cannot write into the publisher's slice.
+	recipients := append([]string(nil), event.UserIDs...)
+
+	if event.ChannelID == "" {
+		return recipients, nil
+	}
+
+	h.mu.RLock()
+	connected := make([]string, 0, len(h.clients))
+	for userID := range h.clients {
+		connected = append(connected, userID)
+	}
+	h.mu.RUnlock()
+
+	if len(connected) == 0 {
+		return recipients, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), audienceTimeout)
+	defer cancel()
+
+	subscribers, err := h.audience.FilterSubscribers(ctx, event.ChannelID, connected)
+	if err != nil {
+		return recipients, errors.Wrap(err, "Hub.recipients")
+	}
+
+	return append(recipients, subscribers...), nil
+}
@@ -0,0 +1,93 @@
+package realtime
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+)
+
+func TestHubClose(t *testing.T) {
+	var (
+		logger   = zerolog.Nop()
+		hub      = NewHub(NewLocalBroker(), nil, &logger)
+		upgrader = &websocket.Upgrader{}
+		served   = make(chan error, 1)
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		served <- hub.Serve(w, r, upgrader, "user")
+	}))
+	defer server.Close()
+
+	url := "ws" + strings.TrimPrefix(server.URL, "http")
+
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	if err := <-served; err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// The client answers the close frame by closing its side, which ends the pumps.
+	go func() {
+		for {
+			_, _, err := conn.ReadMessage()
+			if err != nil {
+				_ = conn.Close()
+				return
+			}
+		}
+	}()
+
+	err = hub.Close(ctx)
+	if err != nil {
+		t.Fatalf("clients were not waited for: %v", err)
+	}
+
+	hub.mu.RLock()
+	connected := len(hub.clients)
+	hub.mu.RUnlock()
+	if connected != 0 {
+		t.Fatalf("%d users are still connected", connected)
+	}
+
+	late, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err == nil {
+		defer late.Close()
+	}
+	if err := <-served; !errors.Is(err, ClosedHubErr) {
+		t.Fatalf("got %v, want ClosedHubErr", err)
+	}
+}
+
+func TestHubCloseDeadline(t *testing.T) {
+	var (
+		logger = zerolog.Nop()
+		hub    = NewHub(NewLocalBroker(), nil, &logger)
+	)
+
+	// A pump that never finishes.
+	hub.pumps.Add(1)
+	defer hub.pumps.Done()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	err := hub.Close(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got %v, want context.DeadlineExceeded", err)
+	}
+}
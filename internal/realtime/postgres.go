@@ -0,0 +1,142 @@
+package realtime
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/petrkoval/social-network-back/internal/domain"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+	"time"
+)
+
+const (
+	notifyChannel  = "realtime_events"
+	reconnectDelay = 5 * time.Second
+
+	// eventRetention is how long published events are kept for the listeners to fetch them.
+	eventRetention = time.Minute
+)
+
+// PostgresBroker fans events out to all instances sharing the database. An event is
+// written to the realtime_events table and only its id is sent via NOTIFY, since
+// PostgreSQL limits the payload of a notification to 8000 bytes.
+type PostgresBroker struct {
+	pool   *pgxpool.Pool
+	logger *zerolog.Logger
+}
+
+func NewPostgresBroker(p *pgxpool.Pool, l *zerolog.Logger) *PostgresBroker {
+	return &PostgresBroker{
+		pool:   p,
+		logger: l,
+	}
+}
+
+func (b *PostgresBroker) Publish(ctx context.Context, event domain.Event) error {
+	payload, err := json.Marshal(event.Payload)
+	if err != nil {
+		return errors.Wrap(err, "PostgresBroker.Publish")
+	}
+
+	query := `WITH event AS (
+				INSERT INTO realtime_events (type, payload, user_ids, channel_id)
+				VALUES ($2, $3, COALESCE($4::uuid[], '{}'), NULLIF($5, '')::uuid)
+				RETURNING event_id
+			)
+			SELECT pg_notify($1, event_id::text) FROM event`
+
+	_, err = b.pool.Exec(ctx, query, notifyChannel, event.Type, payload, event.UserIDs, event.ChannelID)
+	if err != nil {
+		return errors.Wrap(err, "PostgresBroker.Publish")
+	}
+
+	return nil
+}
+
+// Run listens on a dedicated connection and reconnects after failures until ctx is done.
+// Meanwhile it deletes the events older than eventRetention.
+func (b *PostgresBroker) Run(ctx context.Context, deliver func(domain.Event)) error {
+	go b.prune(ctx)
+
+	for {
+		err := b.listen(ctx, deliver)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		b.logger.Error().Err(err).Msg("realtime listener failed, reconnecting")
+
+		select {
+		case <-time.After(reconnectDelay):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (b *PostgresBroker) listen(ctx context.Context, deliver func(domain.Event)) error {
+	pooled, err := b.pool.Acquire(ctx)
+	if err != nil {
+		return errors.Wrap(err, "PostgresBroker.listen")
+	}
+
+	// The connection stays in LISTEN state, so it must not go back to the pool.
+	conn := pooled.Hijack()
+	defer conn.Close(context.Background())
+
+	_, err = conn.Exec(ctx, `LISTEN `+notifyChannel)
+	if err != nil {
+		return errors.Wrap(err, "PostgresBroker.listen")
+	}
+
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return errors.Wrap(err, "PostgresBroker.listen")
+		}
+
+		var (
+			event   domain.Event
+			payload json.RawMessage
+		)
+
+		query := `
+			SELECT type, payload, user_ids::text[], COALESCE(channel_id::text, '')
+			FROM realtime_events
+			WHERE event_id = $1`
+
+		err = conn.QueryRow(ctx, query, notification.Payload).Scan(&event.Type, &payload, &event.UserIDs, &event.ChannelID)
+		if errors.Is(err, pgx.ErrNoRows) {
+			b.logger.Warn().Str("event_id", notification.Payload).Msg("skipping expired realtime event")
+			continue
+		}
+		if err != nil {
+			return errors.Wrap(err, "PostgresBroker.listen")
+		}
+
+		event.Payload = payload
+		deliver(event)
+	}
+}
+
+func (b *PostgresBroker) prune(ctx context.Context) {
+	ticker := time.NewTicker(eventRetention)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+
+		query := `DELETE FROM realtime_events WHERE created_at < now() - make_interval(secs => $1)`
+
+		_, err := b.pool.Exec(ctx, query, eventRetention.Seconds())
+		if err != nil && ctx.Err() == nil {
+			b.logger.Error().Err(err).Msg("failed to prune realtime events")
+		}
+	}
+}
@@ -2,9 +2,11 @@ package services
 
 import (
 	"context"
+	"github.com/google/uuid"
 	"github.com/petrkoval/social-network-back/internal/domain"
 	"github.com/petrkoval/social-network-back/internal/storage"
 	"github.com/pkg/errors"
+	"time"
 )
 
 type AuthResponse struct {
@@ -14,18 +16,20 @@ type AuthResponse struct {
 }
 
 type AuthService struct {
-	tokens *TokenService
-	users  *UserService
+	tokens   *TokenService
+	users    *UserService
+	sessions *SessionService
 }
 
-func NewAuthService(tokenService *TokenService, userService *UserService) *AuthService {
+func NewAuthService(tokenService *TokenService, userService *UserService, sessionService *SessionService) *AuthService {
 	return &AuthService{
-		tokens: tokenService,
-		users:  userService,
+		tokens:   tokenService,
+		users:    userService,
+		sessions: sessionService,
 	}
 }
 
-func (s *AuthService) Register(ctx context.Context, dto domain.CreateUserDTO) (*AuthResponse, error) {
+func (s *AuthService) Register(ctx context.Context, dto domain.CreateUserDTO, client domain.ClientInfo) (*AuthResponse, error) {
 	var (
 		err    error
 		entity *domain.AuthUser
@@ -38,15 +42,23 @@ func (s *AuthService) Register(ctx context.Context, dto domain.CreateUserDTO) (*
 		return nil, errors.Wrap(UserExistsErr, "AuthService.Register")
 	}
 
-	entity, err = s.users.Storage.Create(ctx, dto)
+	dto.Password, err = hashPassword(dto.Password)
 	if err != nil {
 		return nil, errors.Wrap(err, "AuthService.Register")
 	}
 
-	return s.generateAndSaveTokens(ctx, entity)
+	entity, err = s.users.Storage.Create(ctx, dto)
+	if errors.Is(err, storage.DuplicateUsernameErr) {
+		return nil, errors.Wrap(UserExistsErr, "AuthService.Register")
+	} else if err != nil {
+		return nil, errors.Wrap(err, "AuthService.Register")
+	}
+
+	return s.startSession(ctx, entity, client)
 }
 
-func (s *AuthService) Login(ctx context.Context, dto domain.CreateUserDTO) (*AuthResponse, error) {
+// Login does not tell an unknown username from a wrong password, so that it cannot be used to probe for accounts.
+func (s *AuthService) Login(ctx context.Context, dto domain.LoginDTO, client domain.ClientInfo) (*AuthResponse, error) {
 	var (
 		err        error
 		userFromDB *domain.User
@@ -54,28 +66,39 @@ func (s *AuthService) Login(ctx context.Context, dto domain.CreateUserDTO) (*Aut
 	)
 
 	userFromDB, err = s.users.Storage.FindByUsername(ctx, dto.Username)
+	if errors.Is(err, storage.NotFoundUserErr) {
+		return nil, errors.Wrap(InvalidCredentialsErr, "AuthService.Login")
+	}
 	if err != nil {
 		return nil, errors.Wrap(err, "AuthService.Login")
 	}
 
-	if !(dto.Password == userFromDB.Password) {
-		return nil, errors.Wrap(WrongPasswordErr, "AuthService.Login")
+	match, rehash := comparePassword(userFromDB.Password, dto.Password)
+	if !match {
+		return nil, errors.Wrap(InvalidCredentialsErr, "AuthService.Login")
+	}
+
+	if rehash {
+		s.rehashPassword(ctx, userFromDB.ID, dto.Password)
 	}
 
 	entity.Username = userFromDB.Username
 	entity.ID = userFromDB.ID
 
-	return s.generateAndSaveTokens(ctx, entity)
+	return s.startSession(ctx, entity, client)
 }
 
 func (s *AuthService) Logout(ctx context.Context, refreshToken string) error {
-	return s.tokens.Storage.Delete(ctx, refreshToken)
+	return s.sessions.Storage.DeleteByToken(ctx, hashToken(refreshToken))
 }
 
-func (s *AuthService) Refresh(ctx context.Context, refreshToken string) (*AuthResponse, error) {
+// Refresh rotates the refresh token: the presented token is spent and a new pair is
+// issued for the same session. Presenting a spent token again revokes the session.
+func (s *AuthService) Refresh(ctx context.Context, refreshToken string, client domain.ClientInfo) (*AuthResponse, error) {
 	var (
 		err    error
 		entity *domain.AuthUser
+		token  *domain.RefreshToken
 	)
 
 	entity, err = s.tokens.VerifyRefreshToken(refreshToken)
@@ -83,26 +106,94 @@ func (s *AuthService) Refresh(ctx context.Context, refreshToken string) (*AuthRe
 		return nil, errors.Wrap(err, "AuthService.Refresh")
 	}
 
-	_, err = s.tokens.Storage.FindByToken(ctx, refreshToken)
+	token, err = s.sessions.Storage.FindToken(ctx, hashToken(refreshToken))
 	if err != nil {
 		return nil, errors.Wrap(err, "AuthService.Refresh")
 	}
 
-	return s.generateAndSaveTokens(ctx, entity)
+	if token.UserID != entity.ID {
+		return nil, errors.Wrap(InvalidTokenErr, "AuthService.Refresh")
+	}
+
+	if token.RotatedAt != nil {
+		return nil, errors.Wrap(s.revokeFamily(ctx, token, client), "AuthService.Refresh")
+	}
+
+	entity.SessionID = token.FamilyID
+
+	response, err := s.generateTokens(entity)
+	if err != nil {
+		return nil, errors.Wrap(err, "AuthService.Refresh")
+	}
+
+	err = s.sessions.Storage.Rotate(ctx, token.Hash, hashToken(response.RefreshToken), refreshTokenTTL, client)
+	if err != nil {
+		switch {
+		case errors.Is(err, storage.NotFoundTokenErr):
+			// a concurrent request has spent the token in the meantime
+			return nil, errors.Wrap(s.revokeFamily(ctx, token, client), "AuthService.Refresh")
+		default:
+			return nil, errors.Wrap(err, "AuthService.Refresh")
+		}
+	}
+
+	return response, nil
 }
 
-func (s *AuthService) generateAndSaveTokens(ctx context.Context, user *domain.AuthUser) (*AuthResponse, error) {
-	accessToken, refreshToken, err := s.tokens.GenerateTokens(*user)
+func (s *AuthService) revokeFamily(ctx context.Context, token *domain.RefreshToken, client domain.ClientInfo) error {
+	err := s.sessions.RevokeFamily(ctx, token, client)
+	if err != nil && !errors.Is(err, storage.NotFoundSessionErr) {
+		return err
+	}
+
+	return TokenReusedErr
+}
+
+// rehashPassword upgrades a legacy password to the current hash. Failures are
+// only logged: the user has already proven the password, so login goes on.
+func (s *AuthService) rehashPassword(ctx context.Context, userID, password string) {
+	hash, err := hashPassword(password)
 	if err != nil {
-		return nil, errors.Wrap(err, "AuthService.generateAndSaveTokens")
+		s.users.Logger.Warn().Err(err).Str("userID", userID).Msg("failed to rehash password")
+		return
 	}
 
-	err = s.tokens.Storage.Save(ctx, domain.Token{
-		UserID:       user.ID,
-		RefreshToken: refreshToken,
-	})
+	_, err = s.users.Storage.UpdatePassword(ctx, userID, hash)
+	if err != nil {
+		s.users.Logger.Warn().Err(err).Str("userID", userID).Msg("failed to save rehashed password")
+		return
+	}
+
+	s.users.Logger.Debug().Str("userID", userID).Msg("password rehashed")
+}
+
+// startSession opens a new session for the device described by client.
+func (s *AuthService) startSession(ctx context.Context, user *domain.AuthUser, client domain.ClientInfo) (*AuthResponse, error) {
+	user.SessionID = uuid.NewString()
+
+	response, err := s.generateTokens(user)
+	if err != nil {
+		return nil, errors.Wrap(err, "AuthService.startSession")
+	}
+
+	err = s.sessions.Storage.Create(ctx, domain.Session{
+		ID:        user.SessionID,
+		UserID:    user.ID,
+		UserAgent: client.UserAgent,
+		IP:        client.IP,
+		ExpiresAt: time.Now().Add(refreshTokenTTL),
+	}, hashToken(response.RefreshToken))
+	if err != nil {
+		return nil, errors.Wrap(err, "AuthService.startSession")
+	}
+
+	return response, nil
+}
+
+func (s *AuthService) generateTokens(user *domain.AuthUser) (*AuthResponse, error) {
+	accessToken, refreshToken, err := s.tokens.GenerateTokens(*user)
 	if err != nil {
-		return nil, errors.Wrap(err, "AuthService.generateAndSaveTokens")
+		return nil, errors.Wrap(err, "AuthService.generateTokens")
 	}
 
 	return &AuthResponse{
@@ -4,14 +4,14 @@ import (
 	"context"
 	"github.com/petrkoval/social-network-back/internal/config"
 	"github.com/petrkoval/social-network-back/internal/domain"
+	"github.com/petrkoval/social-network-back/pkg/pagination"
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog"
-	"strconv"
 )
 
 type ChannelStorage interface {
-	FindAll(ctx context.Context, limit, offset int) ([]*domain.Channel, error)
-	FindByUserID(ctx context.Context, userID string) ([]*domain.Channel, error)
+	FindAll(ctx context.Context, cursor *pagination.Cursor, limit int) ([]*domain.Channel, error)
+	FindByUserID(ctx context.Context, userID string, cursor *pagination.Cursor, limit int) ([]*domain.Channel, error)
 	FindByID(ctx context.Context, id string) (*domain.Channel, error)
 	Create(ctx context.Context, dto domain.CreateChannelDTO) (*domain.Channel, error)
 	Update(ctx context.Context, id string, dto domain.UpdateChannelDTO) (*domain.Channel, error)
@@ -32,16 +32,76 @@ func NewChannelService(s ChannelStorage, l *zerolog.Logger, c *config.TokensConf
 	}
 }
 
-func (s *ChannelService) FindAll(ctx context.Context, limit, offset string) ([]*domain.Channel, error) {
-	limitInt, err := strconv.Atoi(limit)
+// FindAll returns a page of all channels, newest first.
+func (s *ChannelService) FindAll(ctx context.Context, cursor, limit string) (*pagination.Page[*domain.Channel], error) {
+	params, err := pagination.Parse(cursor, limit)
 	if err != nil {
-		return nil, errors.Wrap(QueryParamParsingErr, "ChannelService.FindAll")
+		return nil, errors.Wrap(err, "ChannelService.FindAll")
 	}
 
-	offsetInt, err := strconv.Atoi(offset)
+	channels, err := s.ChannelStorage.FindAll(ctx, params.Cursor, params.Fetch())
 	if err != nil {
-		return nil, errors.Wrap(QueryParamParsingErr, "ChannelService.FindAll")
+		return nil, errors.Wrap(err, "ChannelService.FindAll")
 	}
 
-	return s.ChannelStorage.FindAll(ctx, limitInt, offsetInt)
+	return pagination.NewPage(channels, params, channelCursor), nil
+}
+
+// FindByUserID returns a page of the channels owned by the user, newest first.
+func (s *ChannelService) FindByUserID(
+	ctx context.Context,
+	userID, cursor, limit string,
+) (*pagination.Page[*domain.Channel], error) {
+	params, err := pagination.Parse(cursor, limit)
+	if err != nil {
+		return nil, errors.Wrap(err, "ChannelService.FindByUserID")
+	}
+
+	channels, err := s.ChannelStorage.FindByUserID(ctx, userID, params.Cursor, params.Fetch())
+	if err != nil {
+		return nil, errors.Wrap(err, "ChannelService.FindByUserID")
+	}
+
+	return pagination.NewPage(channels, params, channelCursor), nil
+}
+
+// Create opens a channel owned by dto.UserID, which callers take from the access token.
+func (s *ChannelService) Create(ctx context.Context, dto domain.CreateChannelDTO) (*domain.Channel, error) {
+	return s.ChannelStorage.Create(ctx, dto)
+}
+
+func (s *ChannelService) Update(ctx context.Context, userID, id string, dto domain.UpdateChannelDTO) (*domain.Channel, error) {
+	err := s.checkOwner(ctx, userID, id)
+	if err != nil {
+		return nil, errors.Wrap(err, "ChannelService.Update")
+	}
+
+	return s.ChannelStorage.Update(ctx, id, dto)
+}
+
+func (s *ChannelService) Delete(ctx context.Context, userID, id string) error {
+	err := s.checkOwner(ctx, userID, id)
+	if err != nil {
+		return errors.Wrap(err, "ChannelService.Delete")
+	}
+
+	return s.ChannelStorage.Delete(ctx, id)
+}
+
+// checkOwner returns ForbiddenErr unless the channel belongs to userID.
+func (s *ChannelService) checkOwner(ctx context.Context, userID, id string) error {
+	channel, err := s.ChannelStorage.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if channel.UserID != userID {
+		return ForbiddenErr
+	}
+
+	return nil
+}
+
+func channelCursor(c *domain.Channel) pagination.Cursor {
+	return pagination.Cursor{CreatedAt: c.CreatedAt, ID: c.ID}
 }
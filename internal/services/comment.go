@@ -0,0 +1,172 @@
+package services
+
+import (
+	"context"
+	"github.com/petrkoval/social-network-back/internal/domain"
+	"github.com/petrkoval/social-network-back/pkg/pagination"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+	"strings"
+)
+
+type CommentStorage interface {
+	FindByID(ctx context.Context, id string) (*domain.Comment, error)
+	FindByPostID(ctx context.Context, postID string, cursor *pagination.Cursor, limit int) ([]*domain.Comment, error)
+	Create(ctx context.Context, dto domain.CreateCommentDTO) (*domain.Comment, error)
+	Update(ctx context.Context, id string, dto domain.UpdateCommentDTO) (*domain.Comment, error)
+	Delete(ctx context.Context, id string) error
+}
+
+type CommentService struct {
+	Storage  CommentStorage
+	posts    postFinder
+	users    userFinder
+	events   EventPublisher
+	notifier Notifier
+	logger   *zerolog.Logger
+}
+
+func NewCommentService(
+	s CommentStorage,
+	p postFinder,
+	u userFinder,
+	e EventPublisher,
+	n Notifier,
+	l *zerolog.Logger,
+) *CommentService {
+	return &CommentService{
+		Storage:  s,
+		posts:    p,
+		users:    u,
+		events:   e,
+		notifier: n,
+		logger:   l,
+	}
+}
+
+// FindByPostID returns a page of comments of the post in chronological order.
+// Replies carry parent_comment_id, so clients assemble threads themselves.
+func (s *CommentService) FindByPostID(ctx context.Context, postID, cursor, limit string) (*pagination.Page[*domain.Comment], error) {
+	params, err := pagination.Parse(cursor, limit)
+	if err != nil {
+		return nil, errors.Wrap(err, "CommentService.FindByPostID")
+	}
+
+	comments, err := s.Storage.FindByPostID(ctx, postID, params.Cursor, params.Fetch())
+	if err != nil {
+		return nil, errors.Wrap(err, "CommentService.FindByPostID")
+	}
+
+	return pagination.NewPage(comments, params, func(c *domain.Comment) pagination.Cursor {
+		return pagination.Cursor{CreatedAt: c.CreatedAt, ID: c.ID}
+	}), nil
+}
+
+// Create adds a comment to the post. A reply must point at a comment of the same post.
+// The post author, the author of the parent comment and the mentioned users are notified.
+func (s *CommentService) Create(ctx context.Context, dto domain.CreateCommentDTO) (*domain.Comment, error) {
+	if strings.TrimSpace(dto.Content) == "" {
+		return nil, errors.Wrap(EmptyCommentErr, "CommentService.Create")
+	}
+
+	post, err := s.posts.FindByID(ctx, dto.UserID, dto.PostID)
+	if err != nil {
+		return nil, errors.Wrap(err, "CommentService.Create")
+	}
+
+	recipients := []string{post.UserID}
+
+	if dto.ParentID != nil {
+		parent, err := s.Storage.FindByID(ctx, *dto.ParentID)
+		if err != nil {
+			return nil, errors.Wrap(err, "CommentService.Create")
+		}
+
+		if parent.PostID != dto.PostID {
+			return nil, errors.Wrap(ForeignParentCommentErr, "CommentService.Create")
+		}
+
+		if parent.UserID != post.UserID {
+			recipients = append(recipients, parent.UserID)
+		}
+	}
+
+	dto.Entities, err = resolveMentions(ctx, s.users, parseEntities(dto.Content))
+	if err != nil {
+		return nil, errors.Wrap(err, "CommentService.Create")
+	}
+
+	comment, err := s.Storage.Create(ctx, dto)
+	if err != nil {
+		return nil, errors.Wrap(err, "CommentService.Create")
+	}
+
+	recipients = without(recipients, dto.UserID)
+
+	publish(ctx, s.events, s.logger, domain.Event{
+		Type:    domain.EventCommentCreated,
+		Payload: comment,
+		UserIDs: recipients,
+	})
+
+	for _, userID := range recipients {
+		s.notifier.Notify(ctx, domain.CreateNotificationDTO{
+			UserID:    userID,
+			ActorID:   dto.UserID,
+			Type:      domain.NotificationComment,
+			SubjectID: dto.PostID,
+		})
+	}
+
+	notifyMentions(ctx, s.notifier, dto.UserID, dto.PostID, mentionedUserIDs(dto.Entities), nil)
+
+	return comment, nil
+}
+
+func (s *CommentService) Update(ctx context.Context, userID, id string, dto domain.UpdateCommentDTO) (*domain.Comment, error) {
+	if strings.TrimSpace(dto.Content) == "" {
+		return nil, errors.Wrap(EmptyCommentErr, "CommentService.Update")
+	}
+
+	previous, err := s.checkAuthor(ctx, userID, id)
+	if err != nil {
+		return nil, errors.Wrap(err, "CommentService.Update")
+	}
+
+	dto.Entities, err = resolveMentions(ctx, s.users, parseEntities(dto.Content))
+	if err != nil {
+		return nil, errors.Wrap(err, "CommentService.Update")
+	}
+
+	comment, err := s.Storage.Update(ctx, id, dto)
+	if err != nil {
+		return nil, errors.Wrap(err, "CommentService.Update")
+	}
+
+	notifyMentions(ctx, s.notifier, userID, comment.PostID, mentionedUserIDs(dto.Entities), mentionedUserIDs(previous.Entities))
+
+	return comment, nil
+}
+
+func (s *CommentService) Delete(ctx context.Context, userID, id string) error {
+	_, err := s.checkAuthor(ctx, userID, id)
+	if err != nil {
+		return errors.Wrap(err, "CommentService.Delete")
+	}
+
+	return s.Storage.Delete(ctx, id)
+}
+
+// checkAuthor returns the comment unless it was written by someone other than userID.
+func (s *CommentService) checkAuthor(ctx context.Context, userID, id string) (*domain.Comment, error) {
+	comment, err := s.Storage.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if comment.UserID != userID {
+		return nil, ForbiddenErr
+	}
+
+	return comment, nil
+}
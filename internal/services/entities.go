@@ -0,0 +1,155 @@
+package services
+
+import (
+	"context"
+	"github.com/petrkoval/social-network-back/internal/domain"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+const (
+	maxHashtagLength  = 64
+	maxUsernameLength = 16
+)
+
+type userFinder interface {
+	FindByUsernames(ctx context.Context, usernames []string) ([]*domain.User, error)
+}
+
+// parseEntities finds #hashtags and @mentions in text. Both have to start the text or
+// follow a character that cannot be part of a word, so e-mail addresses and the like
+// are left alone. Hashtags need at least one letter, which skips things like "#1".
+func parseEntities(text string) []*domain.TextEntity {
+	var (
+		runes    = []rune(text)
+		entities = make([]*domain.TextEntity, 0)
+	)
+
+	for i := 0; i < len(runes); i++ {
+		prefix := runes[i]
+		if prefix != '#' && prefix != '@' {
+			continue
+		}
+
+		if i > 0 && (isWordRune(runes[i-1]) || runes[i-1] == '#' || runes[i-1] == '@') {
+			continue
+		}
+
+		end := i + 1
+		for end < len(runes) && isWordRune(runes[end]) {
+			end++
+		}
+
+		word := runes[i+1 : end]
+
+		switch {
+		case prefix == '#' && len(word) <= maxHashtagLength && hasLetter(word):
+			entities = append(entities, &domain.TextEntity{
+				Type:   domain.EntityHashtag,
+				Offset: i,
+				Length: end - i,
+				Value:  strings.ToLower(string(word)),
+			})
+		case prefix == '@' && len(word) > 0 && len(word) <= maxUsernameLength:
+			entities = append(entities, &domain.TextEntity{
+				Type:   domain.EntityMention,
+				Offset: i,
+				Length: end - i,
+				Value:  string(word),
+			})
+		}
+
+		i = end - 1
+	}
+
+	return entities
+}
+
+// resolveMentions sets the user id of every mention and drops the mentions of
+// usernames that do not exist, which are then rendered as plain text.
+func resolveMentions(ctx context.Context, f userFinder, entities []*domain.TextEntity) ([]*domain.TextEntity, error) {
+	var usernames []string
+	for _, e := range entities {
+		if e.Type == domain.EntityMention {
+			usernames = append(usernames, e.Value)
+		}
+	}
+
+	if len(usernames) == 0 {
+		return entities, nil
+	}
+
+	users, err := f.FindByUsernames(ctx, usernames)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make(map[string]string, len(users))
+	for _, u := range users {
+		ids[u.Username] = u.ID
+	}
+
+	resolved := make([]*domain.TextEntity, 0, len(entities))
+	for _, e := range entities {
+		if e.Type == domain.EntityMention {
+			e.UserID = ids[e.Value]
+			if e.UserID == "" {
+				continue
+			}
+		}
+
+		resolved = append(resolved, e)
+	}
+
+	return resolved, nil
+}
+
+// mentionedUserIDs returns the distinct ids of the users mentioned in entities.
+func mentionedUserIDs(entities []*domain.TextEntity) []string {
+	var (
+		ids  []string
+		seen = make(map[string]bool)
+	)
+
+	for _, e := range entities {
+		if e.Type == domain.EntityMention && !seen[e.UserID] {
+			seen[e.UserID] = true
+			ids = append(ids, e.UserID)
+		}
+	}
+
+	return ids
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.Is(unicode.Mn, r) || r == '_'
+}
+
+func hasLetter(runes []rune) bool {
+	for _, r := range runes {
+		if unicode.IsLetter(r) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// notifyMentions tells the mentioned users that actorID mentioned them in the post or
+// in one of its comments. Users in previous were mentioned before the text was edited
+// and have already been told.
+func notifyMentions(ctx context.Context, n Notifier, actorID, postID string, mentioned, previous []string) {
+	for _, userID := range mentioned {
+		if slices.Contains(previous, userID) {
+			continue
+		}
+
+		n.Notify(ctx, domain.CreateNotificationDTO{
+			UserID:    userID,
+			ActorID:   actorID,
+			Type:      domain.NotificationMention,
+			SubjectID: postID,
+		})
+	}
+}
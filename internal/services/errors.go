@@ -7,9 +7,34 @@ var (
 	UnexpectedSigningMethodErr = errors.New("unexpected signing method")
 	TokenExpiredErr            = errors.New("token is expired")
 	InvalidTokenErr            = errors.New("invalid token")
+	TokenReusedErr             = errors.New("refresh token has already been used")
+	SessionRevokedErr          = errors.New("session has been revoked")
 
-	UserExistsErr    = errors.New("user already exists")
-	WrongPasswordErr = errors.New("wrong password")
+	UserExistsErr         = errors.New("user already exists")
+	WrongPasswordErr      = errors.New("wrong password")
+	PasswordTooLongErr    = errors.New("password is too long")
+	InvalidCredentialsErr = errors.New("invalid username or password")
 
 	QueryParamParsingErr = errors.New("query parameter parsing error")
+
+	ForbiddenErr = errors.New("action is forbidden")
+	EmptyPostErr = errors.New("post has neither content nor media")
+
+	EmptyCommentErr         = errors.New("comment is empty")
+	ForeignParentCommentErr = errors.New("parent comment belongs to another post")
+
+	SelfFollowErr = errors.New("users cannot follow themselves")
+
+	NoConversationMembersErr = errors.New("conversation needs at least one other member")
+	TooManyMembersErr        = errors.New("conversation has too many members")
+	EmptyMessageErr          = errors.New("message is empty")
+
+	MediaTooLargeErr        = errors.New("media file is too large")
+	UnsupportedMediaTypeErr = errors.New("media type is not supported")
+	UnknownMediaErr         = errors.New("media does not exist or belongs to another user")
+	MediaNotReadyErr        = errors.New("media is still being processed")
+
+	EmptySearchQueryErr   = errors.New("search query is empty")
+	SearchQueryTooLongErr = errors.New("search query is too long")
+	UnknownSearchTypeErr  = errors.New("unknown search type")
 )
@@ -0,0 +1,33 @@
+package services
+
+import (
+	"context"
+	"github.com/petrkoval/social-network-back/internal/domain"
+	"github.com/rs/zerolog"
+)
+
+// EventPublisher pushes events to connected clients.
+type EventPublisher interface {
+	Publish(ctx context.Context, event domain.Event) error
+}
+
+// publish delivers events on a best-effort basis: the change they describe is already
+// stored, so a failure is only logged and never fails the request.
+func publish(ctx context.Context, p EventPublisher, l *zerolog.Logger, event domain.Event) {
+	err := p.Publish(ctx, event)
+	if err != nil {
+		l.Warn().Err(err).Str("type", event.Type).Msg("failed to publish event")
+	}
+}
+
+// without returns ids with userID removed, so that users are not notified about their own actions.
+func without(ids []string, userID string) []string {
+	result := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if id != userID {
+			result = append(result, id)
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,47 @@
+package services
+
+import (
+	"context"
+	"github.com/petrkoval/social-network-back/internal/domain"
+	"github.com/petrkoval/social-network-back/pkg/pagination"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+)
+
+// Timeline returns the posts making up the home feed of a user, newest first.
+// The default implementation fans out on read; a precomputed timeline table
+// can be plugged in instead without changing FeedService.
+type Timeline interface {
+	FindForUser(ctx context.Context, userID string, cursor *pagination.Cursor, limit int) ([]*domain.Post, error)
+}
+
+type FeedService struct {
+	timeline Timeline
+	logger   *zerolog.Logger
+}
+
+func NewFeedService(t Timeline, l *zerolog.Logger) *FeedService {
+	return &FeedService{
+		timeline: t,
+		logger:   l,
+	}
+}
+
+// Feed returns a page of posts from users followed by userID and channels the user is subscribed to.
+func (s *FeedService) Feed(ctx context.Context, userID, cursor, limit string) (*pagination.Page[*domain.Post], error) {
+	params, err := pagination.Parse(cursor, limit)
+	if err != nil {
+		return nil, errors.Wrap(err, "FeedService.Feed")
+	}
+
+	posts, err := s.timeline.FindForUser(ctx, userID, params.Cursor, params.Fetch())
+	if err != nil {
+		return nil, errors.Wrap(err, "FeedService.Feed")
+	}
+
+	return pagination.NewPage(posts, params, postCursor), nil
+}
+
+func postCursor(p *domain.Post) pagination.Cursor {
+	return pagination.Cursor{CreatedAt: p.CreatedAt, ID: p.ID}
+}
@@ -0,0 +1,96 @@
+package services
+
+import (
+	"context"
+	"github.com/petrkoval/social-network-back/internal/domain"
+	"github.com/petrkoval/social-network-back/pkg/pagination"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+)
+
+type FollowStorage interface {
+	Create(ctx context.Context, followerID, followeeID string) (bool, error)
+	Delete(ctx context.Context, followerID, followeeID string) error
+	FindFollowers(ctx context.Context, userID string, cursor *pagination.Cursor, limit int) ([]*domain.Follower, error)
+	FindFollowing(ctx context.Context, userID string, cursor *pagination.Cursor, limit int) ([]*domain.Follower, error)
+}
+
+type FollowService struct {
+	Storage  FollowStorage
+	events   EventPublisher
+	notifier Notifier
+	logger   *zerolog.Logger
+}
+
+func NewFollowService(s FollowStorage, e EventPublisher, n Notifier, l *zerolog.Logger) *FollowService {
+	return &FollowService{
+		Storage:  s,
+		events:   e,
+		notifier: n,
+		logger:   l,
+	}
+}
+
+func (s *FollowService) Follow(ctx context.Context, followerID, followeeID string) error {
+	if followerID == followeeID {
+		return errors.Wrap(SelfFollowErr, "FollowService.Follow")
+	}
+
+	created, err := s.Storage.Create(ctx, followerID, followeeID)
+	if err != nil {
+		return errors.Wrap(err, "FollowService.Follow")
+	}
+
+	if created {
+		publish(ctx, s.events, s.logger, domain.Event{
+			Type:    domain.EventFollowerCreated,
+			Payload: map[string]string{"user_id": followerID},
+			UserIDs: []string{followeeID},
+		})
+
+		s.notifier.Notify(ctx, domain.CreateNotificationDTO{
+			UserID:    followeeID,
+			ActorID:   followerID,
+			Type:      domain.NotificationFollow,
+			SubjectID: followeeID,
+		})
+	}
+
+	return nil
+}
+
+func (s *FollowService) Unfollow(ctx context.Context, followerID, followeeID string) error {
+	return s.Storage.Delete(ctx, followerID, followeeID)
+}
+
+func (s *FollowService) Followers(ctx context.Context, userID, cursor, limit string) (*pagination.Page[*domain.Follower], error) {
+	params, err := pagination.Parse(cursor, limit)
+	if err != nil {
+		return nil, errors.Wrap(err, "FollowService.Followers")
+	}
+
+	followers, err := s.Storage.FindFollowers(ctx, userID, params.Cursor, params.Fetch())
+	if err != nil {
+		return nil, errors.Wrap(err, "FollowService.Followers")
+	}
+
+	return pagination.NewPage(followers, params, followerCursor), nil
+}
+
+func (s *FollowService) Following(ctx context.Context, userID, cursor, limit string) (*pagination.Page[*domain.Follower], error) {
+	params, err := pagination.Parse(cursor, limit)
+	if err != nil {
+		return nil, errors.Wrap(err, "FollowService.Following")
+	}
+
+	following, err := s.Storage.FindFollowing(ctx, userID, params.Cursor, params.Fetch())
+	if err != nil {
+		return nil, errors.Wrap(err, "FollowService.Following")
+	}
+
+	return pagination.NewPage(following, params, followerCursor), nil
+}
+
+func followerCursor(f *domain.Follower) pagination.Cursor {
+	return pagination.Cursor{CreatedAt: f.FollowedAt, ID: f.ID}
+}
@@ -0,0 +1,80 @@
+package services
+
+import (
+	"context"
+	"github.com/petrkoval/social-network-back/internal/domain"
+	"github.com/petrkoval/social-network-back/pkg/pagination"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultTrendingWindow = 24 * time.Hour
+	maxTrendingWindow     = 7 * 24 * time.Hour
+	defaultTrendingLimit  = 10
+	maxTrendingLimit      = 50
+)
+
+type HashtagStorage interface {
+	Trending(ctx context.Context, since time.Time, limit int) ([]*domain.Hashtag, error)
+}
+
+type hashtagPostFinder interface {
+	FindByHashtag(ctx context.Context, viewerID, hashtag string, cursor *pagination.Cursor, limit int) ([]*domain.Post, error)
+}
+
+type HashtagService struct {
+	Storage HashtagStorage
+	posts   hashtagPostFinder
+	logger  *zerolog.Logger
+}
+
+func NewHashtagService(s HashtagStorage, p hashtagPostFinder, l *zerolog.Logger) *HashtagService {
+	return &HashtagService{
+		Storage: s,
+		posts:   p,
+		logger:  l,
+	}
+}
+
+// Trending returns the hashtags used by the most posts within the last hours, a day by default.
+func (s *HashtagService) Trending(ctx context.Context, hours, limit string) ([]*domain.Hashtag, error) {
+	window := defaultTrendingWindow
+
+	if hours != "" {
+		h, err := strconv.Atoi(hours)
+		if err != nil || h <= 0 {
+			return nil, errors.Wrap(QueryParamParsingErr, "HashtagService.Trending")
+		}
+
+		window = min(time.Duration(h)*time.Hour, maxTrendingWindow)
+	}
+
+	l, err := pagination.ParseLimit(limit, defaultTrendingLimit, maxTrendingLimit)
+	if err != nil {
+		return nil, errors.Wrap(err, "HashtagService.Trending")
+	}
+
+	return s.Storage.Trending(ctx, time.Now().Add(-window), l)
+}
+
+// Posts returns a page of posts tagged with the hashtag, newest first. The hashtag is
+// matched case-insensitively and may be given with or without the leading '#'.
+func (s *HashtagService) Posts(ctx context.Context, viewerID, hashtag, cursor, limit string) (*pagination.Page[*domain.Post], error) {
+	params, err := pagination.Parse(cursor, limit)
+	if err != nil {
+		return nil, errors.Wrap(err, "HashtagService.Posts")
+	}
+
+	hashtag = strings.ToLower(strings.TrimPrefix(hashtag, "#"))
+
+	posts, err := s.posts.FindByHashtag(ctx, viewerID, hashtag, params.Cursor, params.Fetch())
+	if err != nil {
+		return nil, errors.Wrap(err, "HashtagService.Posts")
+	}
+
+	return pagination.NewPage(posts, params, postCursor), nil
+}
@@ -0,0 +1,73 @@
+package services
+
+import (
+	"context"
+	"github.com/petrkoval/social-network-back/internal/domain"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+)
+
+type LikeStorage interface {
+	Create(ctx context.Context, postID, userID string) (bool, error)
+	Delete(ctx context.Context, postID, userID string) error
+}
+
+type postFinder interface {
+	FindByID(ctx context.Context, viewerID, id string) (*domain.Post, error)
+}
+
+type LikeService struct {
+	Storage  LikeStorage
+	posts    postFinder
+	notifier Notifier
+	logger   *zerolog.Logger
+}
+
+func NewLikeService(s LikeStorage, p postFinder, n Notifier, l *zerolog.Logger) *LikeService {
+	return &LikeService{
+		Storage:  s,
+		posts:    p,
+		notifier: n,
+		logger:   l,
+	}
+}
+
+// Like marks the post as liked by userID and returns the post with fresh counters.
+// Liking the same post twice has no further effect.
+func (s *LikeService) Like(ctx context.Context, userID, postID string) (*domain.Post, error) {
+	created, err := s.Storage.Create(ctx, postID, userID)
+	if err != nil {
+		return nil, errors.Wrap(err, "LikeService.Like")
+	}
+
+	post, err := s.posts.FindByID(ctx, userID, postID)
+	if err != nil {
+		return nil, errors.Wrap(err, "LikeService.Like")
+	}
+
+	if created {
+		s.notifier.Notify(ctx, domain.CreateNotificationDTO{
+			UserID:    post.UserID,
+			ActorID:   userID,
+			Type:      domain.NotificationLike,
+			SubjectID: post.ID,
+		})
+	}
+
+	return post, nil
+}
+
+// Unlike removes the like of userID, if any, and returns the post with fresh counters.
+func (s *LikeService) Unlike(ctx context.Context, userID, postID string) (*domain.Post, error) {
+	err := s.Storage.Delete(ctx, postID, userID)
+	if err != nil {
+		return nil, errors.Wrap(err, "LikeService.Unlike")
+	}
+
+	post, err := s.posts.FindByID(ctx, userID, postID)
+	if err != nil {
+		return nil, errors.Wrap(err, "LikeService.Unlike")
+	}
+
+	return post, nil
+}
@@ -0,0 +1,165 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"github.com/petrkoval/social-network-back/internal/domain"
+	"github.com/petrkoval/social-network-back/pkg/imaging"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+	"io"
+	"net/http"
+)
+
+// allowedMediaTypes are the sniffed content types accepted for upload.
+var allowedMediaTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/gif":  true,
+	"image/webp": true,
+}
+
+// BlobStore keeps file contents by key. Keys are content hashes, so a stored blob never changes.
+type BlobStore interface {
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Exists(ctx context.Context, key string) (bool, error)
+}
+
+type MediaStorage interface {
+	FindByID(ctx context.Context, id string) (*domain.Media, error)
+	FindByIDs(ctx context.Context, ids []string) ([]*domain.Media, error)
+	Create(ctx context.Context, dto domain.CreateMediaDTO) (*domain.Media, error)
+	FindVariant(ctx context.Context, mediaID, name string) (*domain.MediaVariant, error)
+}
+
+// mediaQueue schedules uploaded media for processing.
+type mediaQueue interface {
+	Enqueue(id string)
+}
+
+type mediaFinder interface {
+	FindByIDs(ctx context.Context, ids []string) ([]*domain.Media, error)
+}
+
+type MediaService struct {
+	Storage MediaStorage
+	blobs   BlobStore
+	queue   mediaQueue
+	maxSize int64
+	logger  *zerolog.Logger
+}
+
+func NewMediaService(s MediaStorage, b BlobStore, q mediaQueue, maxSize int64, l *zerolog.Logger) *MediaService {
+	return &MediaService{
+		Storage: s,
+		blobs:   b,
+		queue:   q,
+		maxSize: maxSize,
+		logger:  l,
+	}
+}
+
+// Upload stores a file of userID. The content type is sniffed from the content itself,
+// whatever the client claims, and identical files share a single blob. The media
+// is returned pending, its variants are produced in the background.
+func (s *MediaService) Upload(ctx context.Context, userID string, file io.Reader) (*domain.Media, error) {
+	data, err := io.ReadAll(io.LimitReader(file, s.maxSize+1))
+	if err != nil {
+		return nil, errors.Wrap(err, "MediaService.Upload")
+	}
+
+	if int64(len(data)) > s.maxSize {
+		return nil, errors.Wrap(MediaTooLargeErr, "MediaService.Upload")
+	}
+
+	contentType := http.DetectContentType(data)
+	if !allowedMediaTypes[contentType] {
+		return nil, errors.Wrap(UnsupportedMediaTypeErr, "MediaService.Upload")
+	}
+
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])
+
+	exists, err := s.blobs.Exists(ctx, hash)
+	if err != nil {
+		return nil, errors.Wrap(err, "MediaService.Upload")
+	}
+
+	if !exists {
+		err = s.blobs.Put(ctx, hash, bytes.NewReader(data), int64(len(data)), contentType)
+		if err != nil {
+			return nil, errors.Wrap(err, "MediaService.Upload")
+		}
+	}
+
+	media, err := s.Storage.Create(ctx, domain.CreateMediaDTO{
+		UserID:      userID,
+		ContentHash: hash,
+		ContentType: contentType,
+		Size:        int64(len(data)),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "MediaService.Upload")
+	}
+
+	s.queue.Enqueue(media.ID)
+
+	return media, nil
+}
+
+// Open returns the variant of the media together with its content, which the caller
+// has to close. An empty variant opens the full size image.
+func (s *MediaService) Open(ctx context.Context, id, variant string) (*domain.MediaVariant, io.ReadCloser, error) {
+	if variant == "" {
+		variant = imaging.FullVariant
+	}
+
+	media, err := s.Storage.FindByID(ctx, id)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "MediaService.Open")
+	}
+
+	if media.Status == domain.MediaPending {
+		return nil, nil, errors.Wrap(MediaNotReadyErr, "MediaService.Open")
+	}
+
+	entity, err := s.Storage.FindVariant(ctx, id, variant)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "MediaService.Open")
+	}
+
+	content, err := s.blobs.Get(ctx, entity.ContentHash)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "MediaService.Open")
+	}
+
+	return entity, content, nil
+}
+
+// checkMediaOwner makes sure that every one of ids is a media uploaded by userID.
+func checkMediaOwner(ctx context.Context, f mediaFinder, userID string, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	media, err := f.FindByIDs(ctx, ids)
+	if err != nil {
+		return err
+	}
+
+	owned := make(map[string]bool, len(media))
+	for _, m := range media {
+		owned[m.ID] = m.UserID == userID
+	}
+
+	for _, id := range ids {
+		if !owned[id] {
+			return UnknownMediaErr
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,216 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"github.com/petrkoval/social-network-back/internal/domain"
+	"github.com/petrkoval/social-network-back/pkg/imaging"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+	"io"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+const (
+	mediaQueueSize = 256
+
+	// pendingMediaSweep is how often media left pending, e.g. by a restart or a full
+	// queue, are picked up again; pendingMediaGrace keeps fresh uploads out of the sweep.
+	pendingMediaSweep = time.Minute
+	pendingMediaGrace = time.Minute
+)
+
+// mediaSizes are the thumbnails produced for every image, bounded on the longest side.
+var mediaSizes = []imaging.Size{
+	{Name: "small", MaxDimension: 160},
+	{Name: "medium", MaxDimension: 480},
+	{Name: "large", MaxDimension: 1080},
+}
+
+type MediaProcessingStorage interface {
+	FindByID(ctx context.Context, id string) (*domain.Media, error)
+	FindPending(ctx context.Context, olderThan time.Time) ([]string, error)
+	SaveProcessed(ctx context.Context, id string, dto domain.ProcessedMediaDTO) error
+	MarkFailed(ctx context.Context, id string) error
+}
+
+// MediaProcessor turns uploaded images into variants in the background: the full
+// image stripped of EXIF/GPS metadata and fixed size thumbnails.
+type MediaProcessor struct {
+	Storage MediaProcessingStorage
+	blobs   BlobStore
+	workers int
+	jobs    chan string
+	logger  *zerolog.Logger
+}
+
+func NewMediaProcessor(s MediaProcessingStorage, b BlobStore, workers int, l *zerolog.Logger) *MediaProcessor {
+	return &MediaProcessor{
+		Storage: s,
+		blobs:   b,
+		workers: workers,
+		jobs:    make(chan string, mediaQueueSize),
+		logger:  l,
+	}
+}
+
+// Enqueue schedules the media for processing without blocking. When the queue is
+// full the media stays pending and is picked up by the next sweep.
+func (p *MediaProcessor) Enqueue(id string) {
+	select {
+	case p.jobs <- id:
+	default:
+		p.logger.Warn().Str("media_id", id).Msg("media queue is full, deferring processing")
+	}
+}
+
+// Run processes media until ctx is done and waits for the jobs in progress to finish.
+func (p *MediaProcessor) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+
+	for i := 0; i < p.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for {
+				select {
+				case id := <-p.jobs:
+					p.process(ctx, id)
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+
+	ticker := time.NewTicker(pendingMediaSweep)
+	defer ticker.Stop()
+
+	p.sweep(ctx)
+	for {
+		select {
+		case <-ticker.C:
+			p.sweep(ctx)
+		case <-ctx.Done():
+			wg.Wait()
+			return
+		}
+	}
+}
+
+func (p *MediaProcessor) sweep(ctx context.Context) {
+	ids, err := p.Storage.FindPending(ctx, time.Now().Add(-pendingMediaGrace))
+	if err != nil {
+		p.logger.Error().Err(err).Msg("failed to find pending media")
+		return
+	}
+
+	for _, id := range ids {
+		p.Enqueue(id)
+	}
+}
+
+// process never panics: a crafted image crashing the decoder would otherwise take the
+// server down, and the sweep would pick it up again after the restart.
+func (p *MediaProcessor) process(ctx context.Context, id string) {
+	defer func() {
+		if r := recover(); r != nil {
+			p.logger.Error().Str("media_id", id).Interface("panic", r).Bytes("stack", debug.Stack()).Msg("media processing panicked")
+			p.markFailed(ctx, id)
+		}
+	}()
+
+	err := p.processMedia(ctx, id)
+	if err == nil {
+		return
+	}
+
+	if errors.Is(err, imaging.UnsupportedFormatErr) ||
+		errors.Is(err, imaging.ImageTooLargeErr) ||
+		errors.Is(err, imaging.MalformedImageErr) {
+		p.logger.Warn().Err(err).Str("media_id", id).Msg("media cannot be processed")
+		p.markFailed(ctx, id)
+		return
+	}
+
+	// Anything else, e.g. an unavailable blob store, is retried by the next sweep.
+	p.logger.Error().Stack().Err(err).Str("media_id", id).Msg("failed to process media")
+}
+
+func (p *MediaProcessor) markFailed(ctx context.Context, id string) {
+	err := p.Storage.MarkFailed(ctx, id)
+	if err != nil {
+		p.logger.Error().Err(err).Str("media_id", id).Msg("failed to mark media as failed")
+	}
+}
+
+func (p *MediaProcessor) processMedia(ctx context.Context, id string) error {
+	media, err := p.Storage.FindByID(ctx, id)
+	if err != nil {
+		return errors.Wrap(err, "MediaProcessor.processMedia")
+	}
+
+	if media.Status != domain.MediaPending {
+		return nil
+	}
+
+	original, err := p.blobs.Get(ctx, media.ContentHash)
+	if err != nil {
+		return errors.Wrap(err, "MediaProcessor.processMedia")
+	}
+
+	data, err := io.ReadAll(original)
+	_ = original.Close()
+	if err != nil {
+		return errors.Wrap(err, "MediaProcessor.processMedia")
+	}
+
+	result, err := imaging.Process(data, media.ContentType, mediaSizes)
+	if err != nil {
+		return errors.Wrap(err, "MediaProcessor.processMedia")
+	}
+
+	dto := domain.ProcessedMediaDTO{
+		Width:    result.Width,
+		Height:   result.Height,
+		Blurhash: result.Blurhash,
+		Variants: make([]*domain.MediaVariant, 0, len(result.Variants)),
+	}
+
+	for _, v := range result.Variants {
+		hash, err := p.store(ctx, v.Data, v.ContentType)
+		if err != nil {
+			return errors.Wrap(err, "MediaProcessor.processMedia")
+		}
+
+		dto.Variants = append(dto.Variants, &domain.MediaVariant{
+			MediaID:     id,
+			Name:        v.Name,
+			ContentHash: hash,
+			ContentType: v.ContentType,
+			Size:        int64(len(v.Data)),
+			Width:       v.Width,
+			Height:      v.Height,
+		})
+	}
+
+	return p.Storage.SaveProcessed(ctx, id, dto)
+}
+
+// store puts the content into the blob store under its hash, unless it is already there.
+func (p *MediaProcessor) store(ctx context.Context, data []byte, contentType string) (string, error) {
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])
+
+	exists, err := p.blobs.Exists(ctx, hash)
+	if err != nil || exists {
+		return hash, err
+	}
+
+	return hash, p.blobs.Put(ctx, hash, bytes.NewReader(data), int64(len(data)), contentType)
+}
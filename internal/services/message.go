@@ -0,0 +1,183 @@
+package services
+
+import (
+	"context"
+	"github.com/petrkoval/social-network-back/internal/domain"
+	"github.com/petrkoval/social-network-back/internal/storage"
+	"github.com/petrkoval/social-network-back/pkg/pagination"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+	"slices"
+	"strings"
+)
+
+// maxConversationMembers limits group conversations, including their creator.
+const maxConversationMembers = 32
+
+type ConversationStorage interface {
+	FindByID(ctx context.Context, viewerID, id string) (*domain.Conversation, error)
+	FindByUserID(ctx context.Context, userID string, cursor *pagination.Cursor, limit int) ([]*domain.Conversation, error)
+	Create(ctx context.Context, memberIDs []string, directKey *string) (string, error)
+	MarkRead(ctx context.Context, id, userID, messageID string) (bool, error)
+}
+
+type MessageStorage interface {
+	FindByConversationID(ctx context.Context, conversationID string, cursor *pagination.Cursor, limit int) ([]*domain.Message, error)
+	Create(ctx context.Context, dto domain.SendMessageDTO) (*domain.Message, error)
+}
+
+type MessageService struct {
+	Storage       MessageStorage
+	conversations ConversationStorage
+	events        EventPublisher
+	logger        *zerolog.Logger
+}
+
+func NewMessageService(s MessageStorage, cs ConversationStorage, e EventPublisher, l *zerolog.Logger) *MessageService {
+	return &MessageService{
+		Storage:       s,
+		conversations: cs,
+		events:        e,
+		logger:        l,
+	}
+}
+
+// StartConversation creates a conversation between the user and the given members.
+// Starting a 1:1 conversation that already exists returns the existing one.
+func (s *MessageService) StartConversation(
+	ctx context.Context,
+	userID string,
+	dto domain.CreateConversationDTO,
+) (*domain.Conversation, error) {
+	members := append([]string{userID}, dto.MemberIDs...)
+	slices.Sort(members)
+	members = slices.Compact(members)
+
+	switch {
+	case len(members) < 2:
+		return nil, errors.Wrap(NoConversationMembersErr, "MessageService.StartConversation")
+	case len(members) > maxConversationMembers:
+		return nil, errors.Wrap(TooManyMembersErr, "MessageService.StartConversation")
+	}
+
+	var directKey *string
+	if len(members) == 2 {
+		key := strings.Join(members, ":")
+		directKey = &key
+	}
+
+	id, err := s.conversations.Create(ctx, members, directKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "MessageService.StartConversation")
+	}
+
+	return s.conversations.FindByID(ctx, userID, id)
+}
+
+func (s *MessageService) Conversation(ctx context.Context, userID, id string) (*domain.Conversation, error) {
+	return s.conversations.FindByID(ctx, userID, id)
+}
+
+// Conversations returns a page of conversations of the user, most recently active first.
+func (s *MessageService) Conversations(
+	ctx context.Context,
+	userID, cursor, limit string,
+) (*pagination.Page[*domain.Conversation], error) {
+	params, err := pagination.Parse(cursor, limit)
+	if err != nil {
+		return nil, errors.Wrap(err, "MessageService.Conversations")
+	}
+
+	conversations, err := s.conversations.FindByUserID(ctx, userID, params.Cursor, params.Fetch())
+	if err != nil {
+		return nil, errors.Wrap(err, "MessageService.Conversations")
+	}
+
+	return pagination.NewPage(conversations, params, func(c *domain.Conversation) pagination.Cursor {
+		if c.LastMessageAt != nil {
+			return pagination.Cursor{CreatedAt: *c.LastMessageAt, ID: c.ID}
+		}
+
+		return pagination.Cursor{CreatedAt: c.CreatedAt, ID: c.ID}
+	}), nil
+}
+
+// Messages returns a page of the conversation history, newest first. Only members can read it.
+func (s *MessageService) Messages(
+	ctx context.Context,
+	userID, conversationID, cursor, limit string,
+) (*pagination.Page[*domain.Message], error) {
+	params, err := pagination.Parse(cursor, limit)
+	if err != nil {
+		return nil, errors.Wrap(err, "MessageService.Messages")
+	}
+
+	_, err = s.conversations.FindByID(ctx, userID, conversationID)
+	if err != nil {
+		return nil, errors.Wrap(err, "MessageService.Messages")
+	}
+
+	messages, err := s.Storage.FindByConversationID(ctx, conversationID, params.Cursor, params.Fetch())
+	if err != nil {
+		return nil, errors.Wrap(err, "MessageService.Messages")
+	}
+
+	return pagination.NewPage(messages, params, func(m *domain.Message) pagination.Cursor {
+		return pagination.Cursor{CreatedAt: m.CreatedAt, ID: m.ID}
+	}), nil
+}
+
+// Send stores the message and pushes it to all members, including the other sessions of the sender.
+func (s *MessageService) Send(ctx context.Context, dto domain.SendMessageDTO) (*domain.Message, error) {
+	if strings.TrimSpace(dto.Content) == "" {
+		return nil, errors.Wrap(EmptyMessageErr, "MessageService.Send")
+	}
+
+	// The members are loaded first, so that a failure cannot leave a stored message
+	// that the client is told was not sent.
+	conversation, err := s.conversations.FindByID(ctx, dto.UserID, dto.ConversationID)
+	if err != nil {
+		return nil, errors.Wrap(err, "MessageService.Send")
+	}
+
+	message, err := s.Storage.Create(ctx, dto)
+	if err != nil {
+		return nil, errors.Wrap(err, "MessageService.Send")
+	}
+
+	members := make([]string, 0, len(conversation.Members))
+	for _, m := range conversation.Members {
+		members = append(members, m.UserID)
+	}
+
+	publish(ctx, s.events, s.logger, domain.Event{
+		Type:    domain.EventMessageCreated,
+		Payload: message,
+		UserIDs: members,
+	})
+
+	return message, nil
+}
+
+// MarkRead moves the read receipt of the user and returns the conversation with the updated unread count.
+func (s *MessageService) MarkRead(
+	ctx context.Context,
+	userID, conversationID string,
+	dto domain.MarkReadDTO,
+) (*domain.Conversation, error) {
+	_, err := s.conversations.FindByID(ctx, userID, conversationID)
+	if err != nil {
+		return nil, errors.Wrap(err, "MessageService.MarkRead")
+	}
+
+	found, err := s.conversations.MarkRead(ctx, conversationID, userID, dto.MessageID)
+	if err != nil {
+		return nil, errors.Wrap(err, "MessageService.MarkRead")
+	}
+
+	if !found && dto.MessageID != "" {
+		return nil, errors.Wrap(storage.NotFoundMessageErr, "MessageService.MarkRead")
+	}
+
+	return s.conversations.FindByID(ctx, userID, conversationID)
+}
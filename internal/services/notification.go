@@ -0,0 +1,103 @@
+package services
+
+import (
+	"context"
+	"github.com/petrkoval/social-network-back/internal/domain"
+	"github.com/petrkoval/social-network-back/pkg/pagination"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+	"strconv"
+)
+
+type NotificationStorage interface {
+	Create(ctx context.Context, dto domain.CreateNotificationDTO) error
+	FindByUserID(ctx context.Context, userID string, unreadOnly bool, cursor *pagination.Cursor, limit int) ([]*domain.Notification, error)
+	MarkRead(ctx context.Context, userID string, keys []string) error
+	MarkAllRead(ctx context.Context, userID string) error
+	CountUnread(ctx context.Context, userID string) (int, error)
+}
+
+// Notifier is called by other services when something happens to a user.
+type Notifier interface {
+	Notify(ctx context.Context, dto domain.CreateNotificationDTO)
+}
+
+type NotificationService struct {
+	Storage NotificationStorage
+	events  EventPublisher
+	logger  *zerolog.Logger
+}
+
+func NewNotificationService(s NotificationStorage, e EventPublisher, l *zerolog.Logger) *NotificationService {
+	return &NotificationService{
+		Storage: s,
+		events:  e,
+		logger:  l,
+	}
+}
+
+// Notify stores the notification and pushes it to the recipient. Like publish, it is
+// best-effort: the action it reports has already happened, so failures are only logged.
+// Users are never notified about their own actions.
+func (s *NotificationService) Notify(ctx context.Context, dto domain.CreateNotificationDTO) {
+	if dto.UserID == dto.ActorID {
+		return
+	}
+
+	err := s.Storage.Create(ctx, dto)
+	if err != nil {
+		s.logger.Warn().Err(err).Str("type", dto.Type).Msg("failed to store notification")
+		return
+	}
+
+	publish(ctx, s.events, s.logger, domain.Event{
+		Type: domain.EventNotificationCreated,
+		Payload: map[string]string{
+			"type":       dto.Type,
+			"subject_id": dto.SubjectID,
+			"actor_id":   dto.ActorID,
+		},
+		UserIDs: []string{dto.UserID},
+	})
+}
+
+// Notifications returns a page of notification groups; unread is an optional boolean
+// query parameter restricting them to unread notifications.
+func (s *NotificationService) Notifications(
+	ctx context.Context,
+	userID, unread, cursor, limit string,
+) (*pagination.Page[*domain.Notification], error) {
+	params, err := pagination.Parse(cursor, limit)
+	if err != nil {
+		return nil, errors.Wrap(err, "NotificationService.Notifications")
+	}
+
+	unreadOnly := false
+	if unread != "" {
+		unreadOnly, err = strconv.ParseBool(unread)
+		if err != nil {
+			return nil, errors.Wrap(QueryParamParsingErr, "NotificationService.Notifications")
+		}
+	}
+
+	notifications, err := s.Storage.FindByUserID(ctx, userID, unreadOnly, params.Cursor, params.Fetch())
+	if err != nil {
+		return nil, errors.Wrap(err, "NotificationService.Notifications")
+	}
+
+	return pagination.NewPage(notifications, params, func(n *domain.Notification) pagination.Cursor {
+		return pagination.Cursor{CreatedAt: n.CreatedAt, ID: n.LatestID}
+	}), nil
+}
+
+func (s *NotificationService) MarkRead(ctx context.Context, userID string, dto domain.MarkNotificationsReadDTO) error {
+	if dto.All {
+		return s.Storage.MarkAllRead(ctx, userID)
+	}
+
+	return s.Storage.MarkRead(ctx, userID, dto.Keys)
+}
+
+func (s *NotificationService) CountUnread(ctx context.Context, userID string) (int, error) {
+	return s.Storage.CountUnread(ctx, userID)
+}
@@ -0,0 +1,41 @@
+package services
+
+import (
+	"crypto/subtle"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const passwordHashCost = bcrypt.DefaultCost
+
+func hashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), passwordHashCost)
+	if err != nil {
+		switch {
+		case errors.Is(err, bcrypt.ErrPasswordTooLong):
+			return "", errors.Wrap(PasswordTooLongErr, "hashPassword")
+		default:
+			return "", errors.Wrap(err, "hashPassword")
+		}
+	}
+
+	return string(hash), nil
+}
+
+// comparePassword checks password against the stored value. Rows created before
+// hashing was introduced still hold the plaintext password; they match by a
+// constant-time comparison and are reported as needing a rehash, as are hashes
+// made with an outdated cost.
+func comparePassword(stored, password string) (match, rehash bool) {
+	cost, err := bcrypt.Cost([]byte(stored))
+	if err != nil {
+		match = subtle.ConstantTimeCompare([]byte(stored), []byte(password)) == 1
+		return match, match
+	}
+
+	if bcrypt.CompareHashAndPassword([]byte(stored), []byte(password)) != nil {
+		return false, false
+	}
+
+	return true, cost != passwordHashCost
+}
@@ -0,0 +1,193 @@
+package services
+
+import (
+	"context"
+	"github.com/petrkoval/social-network-back/internal/domain"
+	"github.com/petrkoval/social-network-back/pkg/pagination"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+)
+
+type PostStorage interface {
+	FindByID(ctx context.Context, viewerID, id string) (*domain.Post, error)
+	FindByUserID(ctx context.Context, viewerID, userID string, cursor *pagination.Cursor, limit int) ([]*domain.Post, error)
+	FindByChannelID(ctx context.Context, viewerID, channelID string, cursor *pagination.Cursor, limit int) ([]*domain.Post, error)
+	Create(ctx context.Context, dto domain.CreatePostDTO) (*domain.Post, error)
+	Update(ctx context.Context, viewerID, id string, dto domain.UpdatePostDTO) (*domain.Post, error)
+	Delete(ctx context.Context, id string) error
+}
+
+type channelFinder interface {
+	FindByID(ctx context.Context, id string) (*domain.Channel, error)
+}
+
+type PostService struct {
+	Storage  PostStorage
+	channels channelFinder
+	media    mediaFinder
+	users    userFinder
+	events   EventPublisher
+	notifier Notifier
+	logger   *zerolog.Logger
+}
+
+func NewPostService(
+	s PostStorage,
+	c channelFinder,
+	m mediaFinder,
+	u userFinder,
+	e EventPublisher,
+	n Notifier,
+	l *zerolog.Logger,
+) *PostService {
+	return &PostService{
+		Storage:  s,
+		channels: c,
+		media:    m,
+		users:    u,
+		events:   e,
+		notifier: n,
+		logger:   l,
+	}
+}
+
+// FindByID returns a post as seen by viewerID. An empty viewerID stands for an anonymous viewer.
+func (s *PostService) FindByID(ctx context.Context, viewerID, id string) (*domain.Post, error) {
+	return s.Storage.FindByID(ctx, viewerID, id)
+}
+
+// FindByUserID returns a page of the posts of the user, newest first.
+func (s *PostService) FindByUserID(
+	ctx context.Context,
+	viewerID, userID, cursor, limit string,
+) (*pagination.Page[*domain.Post], error) {
+	params, err := pagination.Parse(cursor, limit)
+	if err != nil {
+		return nil, errors.Wrap(err, "PostService.FindByUserID")
+	}
+
+	posts, err := s.Storage.FindByUserID(ctx, viewerID, userID, params.Cursor, params.Fetch())
+	if err != nil {
+		return nil, errors.Wrap(err, "PostService.FindByUserID")
+	}
+
+	return pagination.NewPage(posts, params, postCursor), nil
+}
+
+// FindByChannelID returns a page of the posts of the channel, newest first.
+func (s *PostService) FindByChannelID(
+	ctx context.Context,
+	viewerID, channelID, cursor, limit string,
+) (*pagination.Page[*domain.Post], error) {
+	params, err := pagination.Parse(cursor, limit)
+	if err != nil {
+		return nil, errors.Wrap(err, "PostService.FindByChannelID")
+	}
+
+	posts, err := s.Storage.FindByChannelID(ctx, viewerID, channelID, params.Cursor, params.Fetch())
+	if err != nil {
+		return nil, errors.Wrap(err, "PostService.FindByChannelID")
+	}
+
+	return pagination.NewPage(posts, params, postCursor), nil
+}
+
+// Create publishes a post on behalf of dto.UserID. Posting into a channel
+// is only allowed for the channel owner, and its subscribers are notified.
+// Hashtags and mentions are parsed from the content; mentioned users are notified.
+func (s *PostService) Create(ctx context.Context, dto domain.CreatePostDTO) (*domain.Post, error) {
+	if dto.Content == "" && len(dto.MediaIDs) == 0 {
+		return nil, errors.Wrap(EmptyPostErr, "PostService.Create")
+	}
+
+	err := checkMediaOwner(ctx, s.media, dto.UserID, dto.MediaIDs)
+	if err != nil {
+		return nil, errors.Wrap(err, "PostService.Create")
+	}
+
+	if dto.ChannelID != nil {
+		channel, err := s.channels.FindByID(ctx, *dto.ChannelID)
+		if err != nil {
+			return nil, errors.Wrap(err, "PostService.Create")
+		}
+
+		if channel.UserID != dto.UserID {
+			return nil, errors.Wrap(ForbiddenErr, "PostService.Create")
+		}
+	}
+
+	dto.Entities, err = resolveMentions(ctx, s.users, parseEntities(dto.Content))
+	if err != nil {
+		return nil, errors.Wrap(err, "PostService.Create")
+	}
+
+	post, err := s.Storage.Create(ctx, dto)
+	if err != nil {
+		return nil, errors.Wrap(err, "PostService.Create")
+	}
+
+	notifyMentions(ctx, s.notifier, dto.UserID, post.ID, mentionedUserIDs(dto.Entities), nil)
+
+	if post.ChannelID != nil {
+		publish(ctx, s.events, s.logger, domain.Event{
+			Type:      domain.EventPostCreated,
+			Payload:   post,
+			ChannelID: *post.ChannelID,
+		})
+	}
+
+	return post, nil
+}
+
+func (s *PostService) Update(ctx context.Context, userID, id string, dto domain.UpdatePostDTO) (*domain.Post, error) {
+	if dto.Content == "" && len(dto.MediaIDs) == 0 {
+		return nil, errors.Wrap(EmptyPostErr, "PostService.Update")
+	}
+
+	previous, err := s.checkAuthor(ctx, userID, id)
+	if err != nil {
+		return nil, errors.Wrap(err, "PostService.Update")
+	}
+
+	err = checkMediaOwner(ctx, s.media, userID, dto.MediaIDs)
+	if err != nil {
+		return nil, errors.Wrap(err, "PostService.Update")
+	}
+
+	dto.Entities, err = resolveMentions(ctx, s.users, parseEntities(dto.Content))
+	if err != nil {
+		return nil, errors.Wrap(err, "PostService.Update")
+	}
+
+	post, err := s.Storage.Update(ctx, userID, id, dto)
+	if err != nil {
+		return nil, errors.Wrap(err, "PostService.Update")
+	}
+
+	notifyMentions(ctx, s.notifier, userID, id, mentionedUserIDs(dto.Entities), mentionedUserIDs(previous.Entities))
+
+	return post, nil
+}
+
+func (s *PostService) Delete(ctx context.Context, userID, id string) error {
+	_, err := s.checkAuthor(ctx, userID, id)
+	if err != nil {
+		return errors.Wrap(err, "PostService.Delete")
+	}
+
+	return s.Storage.Delete(ctx, id)
+}
+
+// checkAuthor returns the post unless it was written by someone other than userID.
+func (s *PostService) checkAuthor(ctx context.Context, userID, id string) (*domain.Post, error) {
+	post, err := s.Storage.FindByID(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if post.UserID != userID {
+		return nil, ForbiddenErr
+	}
+
+	return post, nil
+}
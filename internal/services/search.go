@@ -0,0 +1,80 @@
+package services
+
+import (
+	"context"
+	"github.com/petrkoval/social-network-back/internal/domain"
+	"github.com/petrkoval/social-network-back/pkg/pagination"
+	"github.com/pkg/errors"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	defaultSearchLimit = 10
+	maxSearchLimit     = 50
+	maxSearchLength    = 128
+)
+
+// SearchEngine finds users, channels and posts matching free text, best matches first.
+// The PostgreSQL full-text storage implements it; an external engine can replace it.
+type SearchEngine interface {
+	SearchUsers(ctx context.Context, text string, limit int) ([]*domain.User, error)
+	SearchChannels(ctx context.Context, text string, limit int) ([]*domain.Channel, error)
+	SearchPosts(ctx context.Context, viewerID, text string, limit int) ([]*domain.Post, error)
+}
+
+type SearchService struct {
+	Engine SearchEngine
+}
+
+func NewSearchService(e SearchEngine) *SearchService {
+	return &SearchService{Engine: e}
+}
+
+// Search looks text up among the given type of entities, or among all of them when
+// kind is empty. limit applies to each type separately.
+func (s *SearchService) Search(ctx context.Context, viewerID, text, kind, limit string) (*domain.SearchResults, error) {
+	results := &domain.SearchResults{}
+
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil, errors.Wrap(EmptySearchQueryErr, "SearchService.Search")
+	}
+	if utf8.RuneCountInString(text) > maxSearchLength {
+		return nil, errors.Wrap(SearchQueryTooLongErr, "SearchService.Search")
+	}
+
+	l, err := pagination.ParseLimit(limit, defaultSearchLimit, maxSearchLimit)
+	if err != nil {
+		return nil, errors.Wrap(err, "SearchService.Search")
+	}
+
+	switch kind {
+	case "", domain.SearchUsers, domain.SearchChannels, domain.SearchPosts:
+	default:
+		return nil, errors.Wrap(UnknownSearchTypeErr, "SearchService.Search")
+	}
+
+	if kind == "" || kind == domain.SearchUsers {
+		results.Users, err = s.Engine.SearchUsers(ctx, text, l)
+		if err != nil {
+			return nil, errors.Wrap(err, "SearchService.Search")
+		}
+	}
+
+	if kind == "" || kind == domain.SearchChannels {
+		results.Channels, err = s.Engine.SearchChannels(ctx, text, l)
+		if err != nil {
+			return nil, errors.Wrap(err, "SearchService.Search")
+		}
+	}
+
+	if kind == "" || kind == domain.SearchPosts {
+		results.Posts, err = s.Engine.SearchPosts(ctx, viewerID, text, l)
+		if err != nil {
+			return nil, errors.Wrap(err, "SearchService.Search")
+		}
+	}
+
+	return results, nil
+}
@@ -0,0 +1,75 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"github.com/petrkoval/social-network-back/internal/domain"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+	"time"
+)
+
+type SessionStorage interface {
+	FindToken(ctx context.Context, tokenHash string) (*domain.RefreshToken, error)
+	FindByUserID(ctx context.Context, userID string) ([]*domain.Session, error)
+	Create(ctx context.Context, session domain.Session, tokenHash string) error
+	Rotate(ctx context.Context, oldHash, newHash string, ttl time.Duration, client domain.ClientInfo) error
+	Delete(ctx context.Context, userID, id string) error
+	DeleteByToken(ctx context.Context, tokenHash string) error
+	DeleteByUserID(ctx context.Context, userID string) error
+	DeleteOthers(ctx context.Context, userID, keepID string) error
+}
+
+type SessionService struct {
+	Storage SessionStorage
+	logger  *zerolog.Logger
+}
+
+func NewSessionService(s SessionStorage, l *zerolog.Logger) *SessionService {
+	return &SessionService{
+		Storage: s,
+		logger:  l,
+	}
+}
+
+// FindByUser lists active sessions of the user, marking the one the request was made from.
+func (s *SessionService) FindByUser(ctx context.Context, user *domain.AuthUser) ([]*domain.Session, error) {
+	sessions, err := s.Storage.FindByUserID(ctx, user.ID)
+	if err != nil {
+		return nil, errors.Wrap(err, "SessionService.FindByUser")
+	}
+
+	for _, session := range sessions {
+		session.Current = session.ID == user.SessionID
+	}
+
+	return sessions, nil
+}
+
+func (s *SessionService) Revoke(ctx context.Context, userID, id string) error {
+	return s.Storage.Delete(ctx, userID, id)
+}
+
+func (s *SessionService) RevokeAll(ctx context.Context, userID string) error {
+	return s.Storage.DeleteByUserID(ctx, userID)
+}
+
+// RevokeFamily ends the session a replayed refresh token belongs to. A rotated
+// token being presented again means it leaked, so the event goes to the security log.
+func (s *SessionService) RevokeFamily(ctx context.Context, token *domain.RefreshToken, client domain.ClientInfo) error {
+	s.logger.Warn().
+		Str("event", "refresh_token_reuse").
+		Str("userID", token.UserID).
+		Str("familyID", token.FamilyID).
+		Str("ip", client.IP).
+		Str("userAgent", client.UserAgent).
+		Msg("security: rotated refresh token reused, revoking session")
+
+	return s.Storage.Delete(ctx, token.UserID, token.FamilyID)
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
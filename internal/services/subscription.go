@@ -0,0 +1,51 @@
+package services
+
+import (
+	"context"
+	"github.com/petrkoval/social-network-back/internal/domain"
+	"github.com/petrkoval/social-network-back/pkg/pagination"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+)
+
+type SubscriptionStorage interface {
+	Create(ctx context.Context, userID, channelID string) error
+	Delete(ctx context.Context, userID, channelID string) error
+	FindSubscribers(ctx context.Context, channelID string, cursor *pagination.Cursor, limit int) ([]*domain.Subscriber, error)
+}
+
+type SubscriptionService struct {
+	Storage SubscriptionStorage
+	logger  *zerolog.Logger
+}
+
+func NewSubscriptionService(s SubscriptionStorage, l *zerolog.Logger) *SubscriptionService {
+	return &SubscriptionService{
+		Storage: s,
+		logger:  l,
+	}
+}
+
+func (s *SubscriptionService) Subscribe(ctx context.Context, userID, channelID string) error {
+	return s.Storage.Create(ctx, userID, channelID)
+}
+
+func (s *SubscriptionService) Unsubscribe(ctx context.Context, userID, channelID string) error {
+	return s.Storage.Delete(ctx, userID, channelID)
+}
+
+func (s *SubscriptionService) Subscribers(ctx context.Context, channelID, cursor, limit string) (*pagination.Page[*domain.Subscriber], error) {
+	params, err := pagination.Parse(cursor, limit)
+	if err != nil {
+		return nil, errors.Wrap(err, "SubscriptionService.Subscribers")
+	}
+
+	subscribers, err := s.Storage.FindSubscribers(ctx, channelID, params.Cursor, params.Fetch())
+	if err != nil {
+		return nil, errors.Wrap(err, "SubscriptionService.Subscribers")
+	}
+
+	return pagination.NewPage(subscribers, params, func(s *domain.Subscriber) pagination.Cursor {
+		return pagination.Cursor{CreatedAt: s.SubscribedAt, ID: s.ID}
+	}), nil
+}
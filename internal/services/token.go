@@ -3,6 +3,7 @@ package services
 import (
 	"context"
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 	"github.com/petrkoval/social-network-back/internal/config"
 	"github.com/petrkoval/social-network-back/internal/domain"
 	"github.com/pkg/errors"
@@ -10,24 +11,27 @@ import (
 	"time"
 )
 
-type TokenStorage interface {
-	FindByToken(ctx context.Context, refreshToken string) (*domain.Token, error)
-	FindByUserID(ctx context.Context, userID string) (*domain.Token, error)
-	Save(ctx context.Context, token domain.Token) error
-	Delete(ctx context.Context, refreshToken string) error
+const (
+	accessTokenTTL  = time.Hour * 3
+	refreshTokenTTL = time.Hour * 24 * 30
+)
+
+// sessionChecker tells whether the session an access token was issued for is still active.
+type sessionChecker interface {
+	IsActive(ctx context.Context, id string) (bool, error)
 }
 
 type TokenService struct {
-	Storage TokenStorage
-	logger  *zerolog.Logger
-	cfg     *config.TokensConfig
+	logger   *zerolog.Logger
+	cfg      *config.TokensConfig
+	sessions sessionChecker
 }
 
-func NewTokenService(s TokenStorage, l *zerolog.Logger, cfg *config.TokensConfig) *TokenService {
+func NewTokenService(l *zerolog.Logger, cfg *config.TokensConfig, sessions sessionChecker) *TokenService {
 	return &TokenService{
-		Storage: s,
-		logger:  l,
-		cfg:     cfg,
+		logger:   l,
+		cfg:      cfg,
+		sessions: sessions,
 	}
 }
 
@@ -41,20 +45,24 @@ func (s *TokenService) GenerateTokens(user domain.AuthUser) (accessToken, refres
 	s.logger.Debug().Msg("generating tokens")
 
 	accessTokenClaims = domain.TokenClaims{
-		Username: user.Username,
+		Username:  user.Username,
+		SessionID: user.SessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
 			Issuer:    "token service",
 			Subject:   user.ID,
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour * 3)),
+			ID:        uuid.NewString(),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(accessTokenTTL)),
 		},
 	}
 
 	refreshTokenClaims = domain.TokenClaims{
-		Username: user.Username,
+		Username:  user.Username,
+		SessionID: user.SessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
 			Issuer:    "token service",
 			Subject:   user.ID,
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour * 24 * 30)),
+			ID:        uuid.NewString(),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(refreshTokenTTL)),
 		},
 	}
 
@@ -79,7 +87,9 @@ func (s *TokenService) GenerateTokens(user domain.AuthUser) (accessToken, refres
 	return accessToken, refreshToken, nil
 }
 
-func (s *TokenService) VerifyAccessToken(accessToken string) (*domain.AuthUser, error) {
+// VerifyAccessToken checks the signature and expiry of the token, then that its session
+// has not been revoked: signing out ends the session, and its access tokens with it.
+func (s *TokenService) VerifyAccessToken(ctx context.Context, accessToken string) (*domain.AuthUser, error) {
 	var (
 		token *jwt.Token
 		err   error
@@ -88,17 +98,34 @@ func (s *TokenService) VerifyAccessToken(accessToken string) (*domain.AuthUser,
 
 	token, err = jwt.ParseWithClaims(accessToken, &domain.TokenClaims{}, s.validateAccessSigningMethod)
 	if err != nil {
-		return nil, errors.Wrap(err, "TokenService.VerifyAccessToken")
+		switch {
+		case errors.Is(err, jwt.ErrTokenExpired):
+			return nil, errors.Wrap(TokenExpiredErr, "TokenService.VerifyAccessToken")
+		default:
+			return nil, errors.Wrapf(InvalidTokenErr, "TokenService.VerifyAccessToken: %v", err)
+		}
 	}
 
 	if claims, ok := token.Claims.(*domain.TokenClaims); ok && token.Valid {
 		if claims.ExpiresAt.Before(time.Now()) {
 			return nil, errors.Wrap(TokenExpiredErr, "TokenService.VerifyAccessToken")
 		}
+		if claims.SessionID == "" {
+			return nil, errors.Wrap(InvalidTokenErr, "TokenService.VerifyAccessToken")
+		}
+
+		active, err := s.sessions.IsActive(ctx, claims.SessionID)
+		if err != nil {
+			return nil, errors.Wrap(err, "TokenService.VerifyAccessToken")
+		}
+		if !active {
+			return nil, errors.Wrap(SessionRevokedErr, "TokenService.VerifyAccessToken")
+		}
 
 		entity := &domain.AuthUser{
-			ID:       claims.Subject,
-			Username: claims.Username,
+			ID:        claims.Subject,
+			Username:  claims.Username,
+			SessionID: claims.SessionID,
 		}
 
 		s.logger.Debug().
@@ -135,8 +162,9 @@ func (s *TokenService) VerifyRefreshToken(refreshToken string) (*domain.AuthUser
 		}
 
 		entity := &domain.AuthUser{
-			ID:       claims.Subject,
-			Username: claims.Username,
+			ID:        claims.Subject,
+			Username:  claims.Username,
+			SessionID: claims.SessionID,
 		}
 
 		s.logger.Debug().
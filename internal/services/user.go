@@ -3,6 +3,8 @@ package services
 import (
 	"context"
 	"github.com/petrkoval/social-network-back/internal/domain"
+	"github.com/petrkoval/social-network-back/internal/storage"
+	"github.com/pkg/errors"
 	"github.com/rs/zerolog"
 )
 
@@ -10,18 +12,82 @@ type UserStorage interface {
 	Create(ctx context.Context, dto domain.CreateUserDTO) (*domain.AuthUser, error)
 	FindByID(ctx context.Context, userID string) (*domain.User, error)
 	FindByUsername(ctx context.Context, username string) (*domain.User, error)
-	UpdateUsername(ctx context.Context, userID string, username string) (*domain.User, error)
+	Update(ctx context.Context, userID string, dto domain.UpdateUserDTO) (*domain.User, error)
 	UpdatePassword(ctx context.Context, userID string, password string) (*domain.User, error)
 }
 
 type UserService struct {
-	Storage UserStorage
-	Logger  *zerolog.Logger
+	Storage  UserStorage
+	Logger   *zerolog.Logger
+	sessions SessionStorage
+	media    mediaFinder
 }
 
-func NewUserService(s UserStorage, l *zerolog.Logger) *UserService {
+func NewUserService(s UserStorage, ss SessionStorage, m mediaFinder, l *zerolog.Logger) *UserService {
 	return &UserService{
-		Storage: s,
-		Logger:  l,
+		Storage:  s,
+		Logger:   l,
+		sessions: ss,
+		media:    m,
 	}
 }
+
+func (s *UserService) FindByID(ctx context.Context, id string) (*domain.User, error) {
+	return s.Storage.FindByID(ctx, id)
+}
+
+func (s *UserService) FindByUsername(ctx context.Context, username string) (*domain.User, error) {
+	return s.Storage.FindByUsername(ctx, username)
+}
+
+// Update changes the profile fields present in dto and leaves the others as they are.
+// Everything is checked before the single write, so a rejected update changes nothing;
+// the unique index on usernames settles concurrent claims of the same one.
+func (s *UserService) Update(ctx context.Context, userID string, dto domain.UpdateUserDTO) (*domain.User, error) {
+	if dto.AvatarID != nil && *dto.AvatarID != "" {
+		err := checkMediaOwner(ctx, s.media, userID, []string{*dto.AvatarID})
+		if err != nil {
+			return nil, errors.Wrap(err, "UserService.Update")
+		}
+	}
+
+	_, err := s.Storage.Update(ctx, userID, dto)
+	if errors.Is(err, storage.DuplicateUsernameErr) {
+		return nil, errors.Wrap(UserExistsErr, "UserService.Update")
+	} else if err != nil {
+		return nil, errors.Wrap(err, "UserService.Update")
+	}
+
+	return s.Storage.FindByID(ctx, userID)
+}
+
+// ChangePassword replaces the password after checking the current one and signs
+// the user out everywhere except the session the change was made from.
+func (s *UserService) ChangePassword(ctx context.Context, user *domain.AuthUser, dto domain.ChangePasswordDTO) error {
+	entity, err := s.Storage.FindByID(ctx, user.ID)
+	if err != nil {
+		return errors.Wrap(err, "UserService.ChangePassword")
+	}
+
+	match, _ := comparePassword(entity.Password, dto.CurrentPassword)
+	if !match {
+		return errors.Wrap(WrongPasswordErr, "UserService.ChangePassword")
+	}
+
+	hash, err := hashPassword(dto.NewPassword)
+	if err != nil {
+		return errors.Wrap(err, "UserService.ChangePassword")
+	}
+
+	_, err = s.Storage.UpdatePassword(ctx, user.ID, hash)
+	if err != nil {
+		return errors.Wrap(err, "UserService.ChangePassword")
+	}
+
+	err = s.sessions.DeleteOthers(ctx, user.ID, user.SessionID)
+	if err != nil {
+		return errors.Wrap(err, "UserService.ChangePassword")
+	}
+
+	return nil
+}
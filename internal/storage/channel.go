@@ -2,13 +2,22 @@ package storage
 
 import (
 	"context"
-	"fmt"
 	"github.com/georgysavva/scany/v2/pgxscan"
 	"github.com/jackc/pgx/v5"
 	"github.com/petrkoval/social-network-back/internal/domain"
+	"github.com/petrkoval/social-network-back/pkg/pagination"
 	"github.com/pkg/errors"
 )
 
+// channelColumns selects a channel aliased as c.
+const channelColumns = `
+	c.channel_id,
+	c.user_id,
+	c.created_at,
+	c.title,
+	coalesce(c.description, '') AS description,
+	(SELECT count(*) FROM channel_subscriptions cs WHERE cs.channel_id = c.channel_id) AS subscriber_count`
+
 type ChannelStorage struct {
 	client Client
 }
@@ -17,37 +26,51 @@ func NewChannelStorage(client Client) *ChannelStorage {
 	return &ChannelStorage{client: client}
 }
 
-func (c ChannelStorage) FindAll(ctx context.Context, limit, offset int) ([]*domain.Channel, error) {
+// FindAll returns up to limit channels, newest first, after the cursor.
+func (c ChannelStorage) FindAll(ctx context.Context, cursor *pagination.Cursor, limit int) ([]*domain.Channel, error) {
 	var (
 		channels = make([]*domain.Channel, 0)
 		err      error
-		query    = `SELECT * FROM channels LIMIT $1 OFFSET $2`
+		query    = `
+			SELECT ` + channelColumns + `
+			FROM channels c
+			WHERE ($1::timestamp IS NULL OR (c.created_at, c.channel_id) < ($1, $2::uuid))
+			ORDER BY c.created_at DESC, c.channel_id DESC
+			LIMIT $3`
 	)
 
-	if limit == 0 {
-		err = pgxscan.Select(ctx, c.client, &channels, query, nil, offset)
-		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
-			return nil, errors.Wrap(err, "ChannelStorage.FindAll")
-		}
-	} else {
-		err = pgxscan.Select(ctx, c.client, &channels, query, limit, offset)
-		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
-			return nil, errors.Wrap(err, "ChannelStorage.FindAll")
-		}
+	createdAt, id := cursorArgs(cursor)
+
+	err = pgxscan.Select(ctx, c.client, &channels, query, createdAt, id, limit)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, errors.Wrap(err, "ChannelStorage.FindAll")
 	}
 
 	return channels, nil
 }
 
-func (c ChannelStorage) FindByUserID(ctx context.Context, userID string) ([]*domain.Channel, error) {
+// FindByUserID returns up to limit channels of the user, newest first, after the cursor.
+func (c ChannelStorage) FindByUserID(
+	ctx context.Context,
+	userID string,
+	cursor *pagination.Cursor,
+	limit int,
+) ([]*domain.Channel, error) {
 	var (
 		channels = make([]*domain.Channel, 0)
 		err      error
-		query    = `SELECT * FROM channels WHERE user_id = $1`
+		query    = `
+			SELECT ` + channelColumns + `
+			FROM channels c
+			WHERE c.user_id = $1
+			  AND ($2::timestamp IS NULL OR (c.created_at, c.channel_id) < ($2, $3::uuid))
+			ORDER BY c.created_at DESC, c.channel_id DESC
+			LIMIT $4`
 	)
 
-	fmt.Println(userID)
-	err = pgxscan.Select(ctx, c.client, &channels, query, userID)
+	createdAt, id := cursorArgs(cursor)
+
+	err = pgxscan.Select(ctx, c.client, &channels, query, userID, createdAt, id, limit)
 	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
 		return nil, errors.Wrap(err, "ChannelStorage.FindByUserID")
 	}
@@ -59,7 +82,7 @@ func (c ChannelStorage) FindByID(ctx context.Context, id string) (*domain.Channe
 	var (
 		channel domain.Channel
 		err     error
-		query   = `SELECT * FROM channels WHERE channel_id = $1`
+		query   = `SELECT ` + channelColumns + ` FROM channels c WHERE c.channel_id = $1`
 	)
 
 	err = pgxscan.Get(ctx, c.client, &channel, query, id)
@@ -78,7 +101,7 @@ func (c ChannelStorage) FindByID(ctx context.Context, id string) (*domain.Channe
 func (c ChannelStorage) Create(ctx context.Context, dto domain.CreateChannelDTO) (*domain.Channel, error) {
 	var (
 		channel domain.Channel
-		query   = `INSERT INTO channels (user_id, title, description) VALUES ($1, $2, $3) RETURNING *`
+		query   = `INSERT INTO channels AS c (user_id, title, description) VALUES ($1, $2, $3) RETURNING ` + channelColumns
 	)
 
 	rows, err := c.client.Query(ctx, query, dto.UserID, dto.Title, dto.Description)
@@ -98,7 +121,7 @@ func (c ChannelStorage) Create(ctx context.Context, dto domain.CreateChannelDTO)
 func (c ChannelStorage) Update(ctx context.Context, id string, dto domain.UpdateChannelDTO) (*domain.Channel, error) {
 	var (
 		channel domain.Channel
-		query   = `UPDATE channels SET title = $1, description = $2 WHERE channel_id = $3 RETURNING *`
+		query   = `UPDATE channels AS c SET title = $1, description = $2 WHERE c.channel_id = $3 RETURNING ` + channelColumns
 	)
 
 	rows, err := c.client.Query(ctx, query, dto.Title, dto.Description, id)
@@ -4,9 +4,34 @@ import (
 	"context"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/petrkoval/social-network-back/pkg/pagination"
 )
 
 type Client interface {
 	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
 	Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
 }
+
+const (
+	foreignKeyViolationCode = "23503"
+	uniqueViolationCode     = "23505"
+)
+
+// nullable turns an empty id into SQL NULL so it can be compared against uuid columns.
+func nullable(id string) any {
+	if id == "" {
+		return nil
+	}
+
+	return id
+}
+
+// cursorArgs turns a page cursor into the (created_at, id) query arguments.
+// A nil cursor yields NULLs, which the keyset conditions treat as "from the start".
+func cursorArgs(cursor *pagination.Cursor) (any, any) {
+	if cursor == nil {
+		return nil, nil
+	}
+
+	return cursor.CreatedAt, cursor.ID
+}
@@ -0,0 +1,183 @@
+package storage
+
+import (
+	"context"
+	"github.com/georgysavva/scany/v2/pgxscan"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/petrkoval/social-network-back/internal/domain"
+	"github.com/petrkoval/social-network-back/pkg/pagination"
+	"github.com/pkg/errors"
+)
+
+// commentColumns selects a comment aliased as cm, hiding the content of deleted ones.
+const commentColumns = `
+	cm.comment_id,
+	cm.post_id,
+	cm.user_id,
+	cm.parent_comment_id,
+	CASE WHEN cm.deleted_at IS NULL THEN cm.content ELSE '' END AS content,
+	CASE WHEN cm.deleted_at IS NULL THEN cm.entities ELSE '[]' END AS entities,
+	cm.created_at,
+	cm.updated_at,
+	cm.deleted_at IS NOT NULL AS deleted`
+
+type CommentStorage struct {
+	client Client
+}
+
+func NewCommentStorage(client Client) *CommentStorage {
+	return &CommentStorage{client: client}
+}
+
+func (s *CommentStorage) FindByID(ctx context.Context, id string) (*domain.Comment, error) {
+	var (
+		comment domain.Comment
+		err     error
+		query   = `SELECT ` + commentColumns + ` FROM comments cm WHERE cm.comment_id = $1`
+	)
+
+	err = pgxscan.Get(ctx, s.client, &comment, query, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			return nil, errors.Wrap(NotFoundCommentErr, "CommentStorage.FindByID")
+		default:
+			return nil, errors.Wrap(err, "CommentStorage.FindByID")
+		}
+	}
+
+	return &comment, nil
+}
+
+// FindByPostID returns up to limit comments of the post in chronological order, after the cursor.
+func (s *CommentStorage) FindByPostID(
+	ctx context.Context,
+	postID string,
+	cursor *pagination.Cursor,
+	limit int,
+) ([]*domain.Comment, error) {
+	var (
+		comments = make([]*domain.Comment, 0)
+		err      error
+		query    = `
+			SELECT ` + commentColumns + `
+			FROM comments cm
+			WHERE cm.post_id = $1
+			  AND ($2::timestamp IS NULL OR (cm.created_at, cm.comment_id) > ($2, $3::uuid))
+			ORDER BY cm.created_at, cm.comment_id
+			LIMIT $4`
+	)
+
+	createdAt, id := cursorArgs(cursor)
+
+	err = pgxscan.Select(ctx, s.client, &comments, query, postID, createdAt, id, limit)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, errors.Wrap(err, "CommentStorage.FindByPostID")
+	}
+
+	return comments, nil
+}
+
+func (s *CommentStorage) Create(ctx context.Context, dto domain.CreateCommentDTO) (*domain.Comment, error) {
+	var (
+		comment domain.Comment
+		query   = `
+			WITH cm AS (
+				INSERT INTO comments (post_id, user_id, parent_comment_id, content, entities)
+				VALUES ($1, $2, $3, $4, $5)
+				RETURNING *
+			), mn AS (
+				INSERT INTO mentions (post_id, comment_id, user_id)
+				SELECT cm.post_id, cm.comment_id, unnest($6::uuid[]) FROM cm
+			)
+			SELECT ` + commentColumns + ` FROM cm`
+	)
+
+	_, mentions := entityArgs(dto.Entities)
+
+	rows, err := s.client.Query(ctx, query,
+		dto.PostID, dto.UserID, dto.ParentID, dto.Content, entitiesArg(dto.Entities), mentions)
+	if err != nil {
+		return nil, errors.Wrap(err, "CommentStorage.Create")
+	}
+	defer rows.Close()
+
+	err = pgxscan.ScanOne(&comment, rows)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		switch {
+		case errors.As(err, &pgErr) && pgErr.ConstraintName == "comments_parent_comment_id_fkey":
+			return nil, errors.Wrap(NotFoundCommentErr, "CommentStorage.Create")
+		case errors.As(err, &pgErr) && pgErr.Code == foreignKeyViolationCode:
+			return nil, errors.Wrap(NotFoundPostErr, "CommentStorage.Create")
+		default:
+			return nil, errors.Wrap(err, "CommentStorage.Create")
+		}
+	}
+
+	return &comment, nil
+}
+
+// Update changes the content of a comment that has not been deleted.
+func (s *CommentStorage) Update(ctx context.Context, id string, dto domain.UpdateCommentDTO) (*domain.Comment, error) {
+	var (
+		comment domain.Comment
+		query   = `
+			WITH cm AS (
+				UPDATE comments SET content = $1, entities = $2, updated_at = now()
+				WHERE comment_id = $3 AND deleted_at IS NULL
+				RETURNING *
+			), dm AS (
+				DELETE FROM mentions m USING cm
+				WHERE m.comment_id = cm.comment_id AND m.user_id <> ALL ($4::uuid[])
+			), mn AS (
+				INSERT INTO mentions (post_id, comment_id, user_id)
+				SELECT cm.post_id, cm.comment_id, unnest($4::uuid[]) FROM cm
+				ON CONFLICT (comment_id, user_id) WHERE comment_id IS NOT NULL DO NOTHING
+			)
+			SELECT ` + commentColumns + ` FROM cm`
+	)
+
+	_, mentions := entityArgs(dto.Entities)
+
+	rows, err := s.client.Query(ctx, query, dto.Content, entitiesArg(dto.Entities), id, mentions)
+	if err != nil {
+		return nil, errors.Wrap(err, "CommentStorage.Update")
+	}
+	defer rows.Close()
+
+	err = pgxscan.ScanOne(&comment, rows)
+	if err != nil {
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			return nil, errors.Wrap(NotFoundCommentErr, "CommentStorage.Update")
+		default:
+			return nil, errors.Wrap(err, "CommentStorage.Update")
+		}
+	}
+
+	return &comment, nil
+}
+
+// Delete erases the content of a comment but keeps the row, so replies stay attached to it.
+// The mentions it made go away with the content.
+func (s *CommentStorage) Delete(ctx context.Context, id string) error {
+	var (
+		err   error
+		query = `
+			WITH cm AS (
+				UPDATE comments SET content = '', entities = '[]', deleted_at = now()
+				WHERE comment_id = $1 AND deleted_at IS NULL
+				RETURNING comment_id
+			)
+			DELETE FROM mentions m USING cm WHERE m.comment_id = cm.comment_id`
+	)
+
+	_, err = s.client.Exec(ctx, query, id)
+	if err != nil {
+		return errors.Wrap(err, "CommentStorage.Delete")
+	}
+
+	return nil
+}
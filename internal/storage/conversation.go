@@ -0,0 +1,166 @@
+package storage
+
+import (
+	"context"
+	"github.com/georgysavva/scany/v2/pgxscan"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/petrkoval/social-network-back/internal/domain"
+	"github.com/petrkoval/social-network-back/pkg/pagination"
+	"github.com/pkg/errors"
+)
+
+// conversationColumns selects a conversation aliased as c together with the membership
+// of the viewer aliased as me, which has to be joined by the query.
+const conversationColumns = `
+	c.conversation_id,
+	c.created_at,
+	c.last_message_at,
+	(SELECT json_agg(json_build_object(
+				'user_id', cmb.user_id,
+				'last_read_message_id', cmb.last_read_message_id
+			) ORDER BY cmb.joined_at, cmb.user_id)
+	 FROM conversation_members cmb
+	 WHERE cmb.conversation_id = c.conversation_id) AS members,
+	(SELECT count(*)
+	 FROM messages msg
+	 LEFT JOIN messages lr ON lr.message_id = me.last_read_message_id
+	 WHERE msg.conversation_id = c.conversation_id
+	   AND msg.user_id <> me.user_id
+	   AND (lr.message_id IS NULL OR (msg.created_at, msg.message_id) > (lr.created_at, lr.message_id))) AS unread_count`
+
+type ConversationStorage struct {
+	client Client
+}
+
+func NewConversationStorage(client Client) *ConversationStorage {
+	return &ConversationStorage{client: client}
+}
+
+// FindByID returns the conversation only if viewerID is one of its members.
+func (s *ConversationStorage) FindByID(ctx context.Context, viewerID, id string) (*domain.Conversation, error) {
+	var (
+		conversation domain.Conversation
+		err          error
+		query        = `
+			SELECT ` + conversationColumns + `
+			FROM conversations c
+			JOIN conversation_members me ON me.conversation_id = c.conversation_id AND me.user_id = $1
+			WHERE c.conversation_id = $2`
+	)
+
+	err = pgxscan.Get(ctx, s.client, &conversation, query, viewerID, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			return nil, errors.Wrap(NotFoundConversationErr, "ConversationStorage.FindByID")
+		default:
+			return nil, errors.Wrap(err, "ConversationStorage.FindByID")
+		}
+	}
+
+	return &conversation, nil
+}
+
+// FindByUserID returns up to limit conversations of the user, most recently active first.
+// Conversations without messages are ordered by their creation time.
+func (s *ConversationStorage) FindByUserID(
+	ctx context.Context,
+	userID string,
+	cursor *pagination.Cursor,
+	limit int,
+) ([]*domain.Conversation, error) {
+	var (
+		conversations = make([]*domain.Conversation, 0)
+		err           error
+		query         = `
+			SELECT ` + conversationColumns + `
+			FROM conversations c
+			JOIN conversation_members me ON me.conversation_id = c.conversation_id AND me.user_id = $1
+			WHERE $2::timestamp IS NULL
+			   OR (coalesce(c.last_message_at, c.created_at), c.conversation_id) < ($2, $3::uuid)
+			ORDER BY coalesce(c.last_message_at, c.created_at) DESC, c.conversation_id DESC
+			LIMIT $4`
+	)
+
+	createdAt, id := cursorArgs(cursor)
+
+	err = pgxscan.Select(ctx, s.client, &conversations, query, userID, createdAt, id, limit)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, errors.Wrap(err, "ConversationStorage.FindByUserID")
+	}
+
+	return conversations, nil
+}
+
+// Create starts a conversation between memberIDs and returns its id. A non-nil directKey
+// identifies a 1:1 conversation: if one already exists for the key, its id is returned instead.
+func (s *ConversationStorage) Create(ctx context.Context, memberIDs []string, directKey *string) (string, error) {
+	var (
+		id    string
+		err   error
+		query = `
+			WITH created AS (
+				INSERT INTO conversations (direct_key) VALUES ($2)
+				ON CONFLICT (direct_key) DO NOTHING
+				RETURNING conversation_id
+			), members AS (
+				INSERT INTO conversation_members (conversation_id, user_id)
+				SELECT created.conversation_id, member.user_id
+				FROM created, unnest($1::uuid[]) AS member(user_id)
+			)
+			SELECT conversation_id FROM created
+			UNION ALL
+			SELECT conversation_id FROM conversations
+			WHERE direct_key = $2 AND NOT EXISTS (SELECT 1 FROM created)`
+	)
+
+	err = pgxscan.Get(ctx, s.client, &id, query, memberIDs, directKey)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		switch {
+		case errors.As(err, &pgErr) && pgErr.Code == foreignKeyViolationCode:
+			return "", errors.Wrap(NotFoundUserErr, "ConversationStorage.Create")
+		default:
+			return "", errors.Wrap(err, "ConversationStorage.Create")
+		}
+	}
+
+	return id, nil
+}
+
+// MarkRead moves the read receipt of the member to messageID, or to the latest message of
+// the conversation when messageID is empty. The receipt never moves backwards.
+// It reports whether the target message exists in the conversation.
+func (s *ConversationStorage) MarkRead(ctx context.Context, id, userID, messageID string) (bool, error) {
+	var (
+		found bool
+		err   error
+		query = `
+			WITH target AS (
+				SELECT message_id, created_at
+				FROM messages
+				WHERE conversation_id = $1 AND ($3::uuid IS NULL OR message_id = $3)
+				ORDER BY created_at DESC, message_id DESC
+				LIMIT 1
+			), updated AS (
+				UPDATE conversation_members AS me SET last_read_message_id = target.message_id
+				FROM target
+				WHERE me.conversation_id = $1
+				  AND me.user_id = $2
+				  AND NOT EXISTS (
+					SELECT 1 FROM messages cur
+					WHERE cur.message_id = me.last_read_message_id
+					  AND (cur.created_at, cur.message_id) >= (target.created_at, target.message_id)
+				  )
+			)
+			SELECT EXISTS (SELECT 1 FROM target)`
+	)
+
+	err = pgxscan.Get(ctx, s.client, &found, query, id, userID, nullable(messageID))
+	if err != nil {
+		return false, errors.Wrap(err, "ConversationStorage.MarkRead")
+	}
+
+	return found, nil
+}
@@ -6,5 +6,20 @@ var (
 	NotFoundUserErr  = errors.New("no user found")
 	NotFoundTokenErr = errors.New("no token found")
 
+	// DuplicateUsernameErr is turned into services.UserExistsErr before it reaches a handler.
+	DuplicateUsernameErr = errors.New("username is taken")
+
+	NotFoundSessionErr = errors.New("no session found")
+
 	NotFoundChannelErr = errors.New("no channel found")
+
+	NotFoundPostErr = errors.New("no post found")
+
+	NotFoundCommentErr = errors.New("no comment found")
+
+	NotFoundConversationErr = errors.New("no conversation found")
+	NotFoundMessageErr      = errors.New("no message found")
+
+	NotFoundMediaErr        = errors.New("no media found")
+	NotFoundMediaVariantErr = errors.New("no media variant found")
 )
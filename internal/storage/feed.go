@@ -0,0 +1,49 @@
+package storage
+
+import (
+	"context"
+	"github.com/georgysavva/scany/v2/pgxscan"
+	"github.com/jackc/pgx/v5"
+	"github.com/petrkoval/social-network-back/internal/domain"
+	"github.com/petrkoval/social-network-back/pkg/pagination"
+	"github.com/pkg/errors"
+)
+
+// FeedStorage builds home feeds on read: every request collects the posts of
+// followed users and subscribed channels straight from the posts table.
+type FeedStorage struct {
+	client Client
+}
+
+func NewFeedStorage(client Client) *FeedStorage {
+	return &FeedStorage{client: client}
+}
+
+func (s *FeedStorage) FindForUser(
+	ctx context.Context,
+	userID string,
+	cursor *pagination.Cursor,
+	limit int,
+) ([]*domain.Post, error) {
+	var (
+		posts = make([]*domain.Post, 0)
+		err   error
+		query = `
+			SELECT ` + postColumns + `
+			FROM posts p
+			WHERE (p.user_id IN (SELECT followee_id FROM follows WHERE follower_id = $1)
+				OR p.channel_id IN (SELECT channel_id FROM channel_subscriptions WHERE user_id = $1))
+			  AND ($2::timestamp IS NULL OR (p.created_at, p.post_id) < ($2, $3::uuid))
+			ORDER BY p.created_at DESC, p.post_id DESC
+			LIMIT $4`
+	)
+
+	createdAt, id := cursorArgs(cursor)
+
+	err = pgxscan.Select(ctx, s.client, &posts, query, userID, createdAt, id, limit)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, errors.Wrap(err, "FeedStorage.FindForUser")
+	}
+
+	return posts, nil
+}
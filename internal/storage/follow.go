@@ -0,0 +1,115 @@
+package storage
+
+import (
+	"context"
+	"github.com/georgysavva/scany/v2/pgxscan"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/petrkoval/social-network-back/internal/domain"
+	"github.com/petrkoval/social-network-back/pkg/pagination"
+	"github.com/pkg/errors"
+)
+
+type FollowStorage struct {
+	client Client
+}
+
+func NewFollowStorage(client Client) *FollowStorage {
+	return &FollowStorage{client: client}
+}
+
+// Create is idempotent: following an already followed user is a no-op.
+// It reports whether a new follow was added.
+func (s *FollowStorage) Create(ctx context.Context, followerID, followeeID string) (bool, error) {
+	var (
+		err   error
+		tag   pgconn.CommandTag
+		query = `INSERT INTO follows (follower_id, followee_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`
+	)
+
+	tag, err = s.client.Exec(ctx, query, followerID, followeeID)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == foreignKeyViolationCode {
+			return false, errors.Wrap(NotFoundUserErr, "FollowStorage.Create")
+		}
+
+		return false, errors.Wrap(err, "FollowStorage.Create")
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
+
+func (s *FollowStorage) Delete(ctx context.Context, followerID, followeeID string) error {
+	var (
+		err   error
+		query = `DELETE FROM follows WHERE follower_id = $1 AND followee_id = $2`
+	)
+
+	_, err = s.client.Exec(ctx, query, followerID, followeeID)
+	if err != nil {
+		return errors.Wrap(err, "FollowStorage.Delete")
+	}
+
+	return nil
+}
+
+// FindFollowers returns up to limit users following userID, newest first, after the cursor.
+func (s *FollowStorage) FindFollowers(
+	ctx context.Context,
+	userID string,
+	cursor *pagination.Cursor,
+	limit int,
+) ([]*domain.Follower, error) {
+	var (
+		followers = make([]*domain.Follower, 0)
+		err       error
+		query     = `
+			SELECT ` + profileColumns + `, f.created_at AS followed_at
+			FROM follows f
+					 JOIN users u ON u.user_id = f.follower_id
+			WHERE f.followee_id = $1
+			  AND ($2::timestamp IS NULL OR (f.created_at, f.follower_id) < ($2, $3::uuid))
+			ORDER BY f.created_at DESC, f.follower_id DESC
+			LIMIT $4`
+	)
+
+	createdAt, id := cursorArgs(cursor)
+
+	err = pgxscan.Select(ctx, s.client, &followers, query, userID, createdAt, id, limit)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, errors.Wrap(err, "FollowStorage.FindFollowers")
+	}
+
+	return followers, nil
+}
+
+// FindFollowing returns up to limit users followed by userID, newest first, after the cursor.
+func (s *FollowStorage) FindFollowing(
+	ctx context.Context,
+	userID string,
+	cursor *pagination.Cursor,
+	limit int,
+) ([]*domain.Follower, error) {
+	var (
+		following = make([]*domain.Follower, 0)
+		err       error
+		query     = `
+			SELECT ` + profileColumns + `, f.created_at AS followed_at
+			FROM follows f
+					 JOIN users u ON u.user_id = f.followee_id
+			WHERE f.follower_id = $1
+			  AND ($2::timestamp IS NULL OR (f.created_at, f.followee_id) < ($2, $3::uuid))
+			ORDER BY f.created_at DESC, f.followee_id DESC
+			LIMIT $4`
+	)
+
+	createdAt, id := cursorArgs(cursor)
+
+	err = pgxscan.Select(ctx, s.client, &following, query, userID, createdAt, id, limit)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, errors.Wrap(err, "FollowStorage.FindFollowing")
+	}
+
+	return following, nil
+}
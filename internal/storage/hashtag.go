@@ -0,0 +1,73 @@
+package storage
+
+import (
+	"context"
+	"github.com/georgysavva/scany/v2/pgxscan"
+	"github.com/jackc/pgx/v5"
+	"github.com/petrkoval/social-network-back/internal/domain"
+	"github.com/pkg/errors"
+	"time"
+)
+
+type HashtagStorage struct {
+	client Client
+}
+
+func NewHashtagStorage(client Client) *HashtagStorage {
+	return &HashtagStorage{client: client}
+}
+
+// Trending returns up to limit hashtags used by the most posts created since the given time.
+func (s *HashtagStorage) Trending(ctx context.Context, since time.Time, limit int) ([]*domain.Hashtag, error) {
+	var (
+		hashtags = make([]*domain.Hashtag, 0)
+		err      error
+		query    = `
+			SELECT h.name, count(*) AS post_count
+			FROM post_hashtags ph
+			JOIN hashtags h ON h.hashtag_id = ph.hashtag_id
+			WHERE ph.created_at >= $1
+			GROUP BY h.hashtag_id, h.name
+			ORDER BY post_count DESC, max(ph.created_at) DESC
+			LIMIT $2`
+	)
+
+	err = pgxscan.Select(ctx, s.client, &hashtags, query, since, limit)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, errors.Wrap(err, "HashtagStorage.Trending")
+	}
+
+	return hashtags, nil
+}
+
+// entityArgs returns the distinct hashtags and mentioned user ids of entities,
+// which are stored alongside the text they were parsed from.
+func entityArgs(entities []*domain.TextEntity) ([]string, []string) {
+	var (
+		hashtags = make([]string, 0)
+		mentions = make([]string, 0)
+		seen     = make(map[string]bool)
+	)
+
+	for _, e := range entities {
+		switch {
+		case e.Type == domain.EntityHashtag && !seen["#"+e.Value]:
+			seen["#"+e.Value] = true
+			hashtags = append(hashtags, e.Value)
+		case e.Type == domain.EntityMention && !seen["@"+e.UserID]:
+			seen["@"+e.UserID] = true
+			mentions = append(mentions, e.UserID)
+		}
+	}
+
+	return hashtags, mentions
+}
+
+// entitiesArg makes sure that a text without entities is stored as an empty JSON array rather than null.
+func entitiesArg(entities []*domain.TextEntity) []*domain.TextEntity {
+	if entities == nil {
+		return make([]*domain.TextEntity, 0)
+	}
+
+	return entities
+}
@@ -0,0 +1,51 @@
+package storage
+
+import (
+	"context"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/pkg/errors"
+)
+
+type LikeStorage struct {
+	client Client
+}
+
+func NewLikeStorage(client Client) *LikeStorage {
+	return &LikeStorage{client: client}
+}
+
+// Create is idempotent: liking an already liked post is a no-op.
+// It reports whether a new like was added.
+func (s *LikeStorage) Create(ctx context.Context, postID, userID string) (bool, error) {
+	var (
+		err   error
+		tag   pgconn.CommandTag
+		query = `INSERT INTO likes (post_id, user_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`
+	)
+
+	tag, err = s.client.Exec(ctx, query, postID, userID)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == foreignKeyViolationCode {
+			return false, errors.Wrap(NotFoundPostErr, "LikeStorage.Create")
+		}
+
+		return false, errors.Wrap(err, "LikeStorage.Create")
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
+
+func (s *LikeStorage) Delete(ctx context.Context, postID, userID string) error {
+	var (
+		err   error
+		query = `DELETE FROM likes WHERE post_id = $1 AND user_id = $2`
+	)
+
+	_, err = s.client.Exec(ctx, query, postID, userID)
+	if err != nil {
+		return errors.Wrap(err, "LikeStorage.Delete")
+	}
+
+	return nil
+}
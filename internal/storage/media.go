@@ -0,0 +1,189 @@
+package storage
+
+import (
+	"context"
+	"github.com/georgysavva/scany/v2/pgxscan"
+	"github.com/jackc/pgx/v5"
+	"github.com/petrkoval/social-network-back/internal/domain"
+	"github.com/pkg/errors"
+	"time"
+)
+
+const mediaColumns = `
+	m.media_id,
+	m.user_id,
+	m.content_hash,
+	m.content_type,
+	m.size,
+	m.status,
+	m.width,
+	m.height,
+	m.blurhash,
+	m.created_at`
+
+type MediaStorage struct {
+	client Client
+}
+
+func NewMediaStorage(client Client) *MediaStorage {
+	return &MediaStorage{client: client}
+}
+
+func (s *MediaStorage) FindByID(ctx context.Context, id string) (*domain.Media, error) {
+	var (
+		media domain.Media
+		err   error
+		query = `SELECT ` + mediaColumns + ` FROM media m WHERE m.media_id = $1`
+	)
+
+	err = pgxscan.Get(ctx, s.client, &media, query, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			return nil, errors.Wrap(NotFoundMediaErr, "MediaStorage.FindByID")
+		default:
+			return nil, errors.Wrap(err, "MediaStorage.FindByID")
+		}
+	}
+
+	return &media, nil
+}
+
+// FindByIDs returns the existing media among ids, in no particular order.
+func (s *MediaStorage) FindByIDs(ctx context.Context, ids []string) ([]*domain.Media, error) {
+	var (
+		media = make([]*domain.Media, 0)
+		err   error
+		query = `SELECT ` + mediaColumns + ` FROM media m WHERE m.media_id = ANY($1::uuid[])`
+	)
+
+	err = pgxscan.Select(ctx, s.client, &media, query, ids)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, errors.Wrap(err, "MediaStorage.FindByIDs")
+	}
+
+	return media, nil
+}
+
+func (s *MediaStorage) Create(ctx context.Context, dto domain.CreateMediaDTO) (*domain.Media, error) {
+	var (
+		media domain.Media
+		query = `
+			INSERT INTO media AS m (user_id, content_hash, content_type, size)
+			VALUES ($1, $2, $3, $4)
+			RETURNING ` + mediaColumns
+	)
+
+	rows, err := s.client.Query(ctx, query, dto.UserID, dto.ContentHash, dto.ContentType, dto.Size)
+	if err != nil {
+		return nil, errors.Wrap(err, "MediaStorage.Create")
+	}
+	defer rows.Close()
+
+	err = pgxscan.ScanOne(&media, rows)
+	if err != nil {
+		return nil, errors.Wrap(err, "MediaStorage.Create")
+	}
+
+	return &media, nil
+}
+
+// FindPending returns the ids of media uploaded before olderThan that are still waiting to be processed.
+func (s *MediaStorage) FindPending(ctx context.Context, olderThan time.Time) ([]string, error) {
+	var (
+		ids   = make([]string, 0)
+		err   error
+		query = `SELECT media_id FROM media WHERE status = 'pending' AND created_at < $1 ORDER BY created_at`
+	)
+
+	err = pgxscan.Select(ctx, s.client, &ids, query, olderThan)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, errors.Wrap(err, "MediaStorage.FindPending")
+	}
+
+	return ids, nil
+}
+
+func (s *MediaStorage) FindVariant(ctx context.Context, mediaID, name string) (*domain.MediaVariant, error) {
+	var (
+		variant domain.MediaVariant
+		err     error
+		query   = `
+			SELECT media_id, name, content_hash, content_type, size, width, height
+			FROM media_variants
+			WHERE media_id = $1 AND name = $2`
+	)
+
+	err = pgxscan.Get(ctx, s.client, &variant, query, mediaID, name)
+	if err != nil {
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			return nil, errors.Wrap(NotFoundMediaVariantErr, "MediaStorage.FindVariant")
+		default:
+			return nil, errors.Wrap(err, "MediaStorage.FindVariant")
+		}
+	}
+
+	return &variant, nil
+}
+
+// SaveProcessed records the dimensions and variants of a media and marks it ready.
+func (s *MediaStorage) SaveProcessed(ctx context.Context, id string, dto domain.ProcessedMediaDTO) error {
+	var (
+		err     error
+		names   = make([]string, 0, len(dto.Variants))
+		hashes  = make([]string, 0, len(dto.Variants))
+		types   = make([]string, 0, len(dto.Variants))
+		sizes   = make([]int64, 0, len(dto.Variants))
+		widths  = make([]int, 0, len(dto.Variants))
+		heights = make([]int, 0, len(dto.Variants))
+		query   = `
+			WITH processed AS (
+				UPDATE media SET status = 'ready', width = $2, height = $3, blurhash = $4
+				WHERE media_id = $1
+				RETURNING media_id
+			)
+			INSERT INTO media_variants (media_id, name, content_hash, content_type, size, width, height)
+			SELECT processed.media_id, v.name, v.content_hash, v.content_type, v.size, v.width, v.height
+			FROM processed,
+				 unnest($5::text[], $6::text[], $7::text[], $8::bigint[], $9::int[], $10::int[])
+					 AS v(name, content_hash, content_type, size, width, height)
+			ON CONFLICT (media_id, name) DO UPDATE SET
+				content_hash = excluded.content_hash,
+				content_type = excluded.content_type,
+				size = excluded.size,
+				width = excluded.width,
+				height = excluded.height`
+	)
+
+	for _, v := range dto.Variants {
+		names = append(names, v.Name)
+		hashes = append(hashes, v.ContentHash)
+		types = append(types, v.ContentType)
+		sizes = append(sizes, v.Size)
+		widths = append(widths, v.Width)
+		heights = append(heights, v.Height)
+	}
+
+	_, err = s.client.Exec(ctx, query, id, dto.Width, dto.Height, dto.Blurhash,
+		names, hashes, types, sizes, widths, heights)
+	if err != nil {
+		return errors.Wrap(err, "MediaStorage.SaveProcessed")
+	}
+
+	return nil
+}
+
+func (s *MediaStorage) MarkFailed(ctx context.Context, id string) error {
+	var (
+		err   error
+		query = `UPDATE media SET status = 'failed' WHERE media_id = $1`
+	)
+
+	_, err = s.client.Exec(ctx, query, id)
+	if err != nil {
+		return errors.Wrap(err, "MediaStorage.MarkFailed")
+	}
+
+	return nil
+}
@@ -0,0 +1,92 @@
+package storage
+
+import (
+	"context"
+	"github.com/georgysavva/scany/v2/pgxscan"
+	"github.com/jackc/pgx/v5"
+	"github.com/petrkoval/social-network-back/internal/domain"
+	"github.com/petrkoval/social-network-back/pkg/pagination"
+	"github.com/pkg/errors"
+)
+
+type MessageStorage struct {
+	client Client
+}
+
+func NewMessageStorage(client Client) *MessageStorage {
+	return &MessageStorage{client: client}
+}
+
+// FindByConversationID returns up to limit messages of the conversation, newest first.
+func (s *MessageStorage) FindByConversationID(
+	ctx context.Context,
+	conversationID string,
+	cursor *pagination.Cursor,
+	limit int,
+) ([]*domain.Message, error) {
+	var (
+		messages = make([]*domain.Message, 0)
+		err      error
+		query    = `
+			SELECT m.message_id, m.conversation_id, m.user_id, m.content, m.created_at
+			FROM messages m
+			WHERE m.conversation_id = $1
+			  AND ($2::timestamp IS NULL OR (m.created_at, m.message_id) < ($2, $3::uuid))
+			ORDER BY m.created_at DESC, m.message_id DESC
+			LIMIT $4`
+	)
+
+	createdAt, id := cursorArgs(cursor)
+
+	err = pgxscan.Select(ctx, s.client, &messages, query, conversationID, createdAt, id, limit)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, errors.Wrap(err, "MessageStorage.FindByConversationID")
+	}
+
+	return messages, nil
+}
+
+// Create stores a message sent by a member of the conversation, bumps the conversation
+// activity and marks the message as read by its sender.
+func (s *MessageStorage) Create(ctx context.Context, dto domain.SendMessageDTO) (*domain.Message, error) {
+	var (
+		message domain.Message
+		query   = `
+			WITH sent AS (
+				INSERT INTO messages (conversation_id, user_id, content)
+				SELECT $1::uuid, $2::uuid, $3::text
+				WHERE EXISTS (
+					SELECT 1 FROM conversation_members
+					WHERE conversation_id = $1 AND user_id = $2
+				)
+				RETURNING message_id, conversation_id, user_id, content, created_at
+			), activity AS (
+				UPDATE conversations c SET last_message_at = sent.created_at
+				FROM sent
+				WHERE c.conversation_id = sent.conversation_id
+			), receipt AS (
+				UPDATE conversation_members me SET last_read_message_id = sent.message_id
+				FROM sent
+				WHERE me.conversation_id = sent.conversation_id AND me.user_id = sent.user_id
+			)
+			SELECT message_id, conversation_id, user_id, content, created_at FROM sent`
+	)
+
+	rows, err := s.client.Query(ctx, query, dto.ConversationID, dto.UserID, dto.Content)
+	if err != nil {
+		return nil, errors.Wrap(err, "MessageStorage.Create")
+	}
+	defer rows.Close()
+
+	err = pgxscan.ScanOne(&message, rows)
+	if err != nil {
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			return nil, errors.Wrap(NotFoundConversationErr, "MessageStorage.Create")
+		default:
+			return nil, errors.Wrap(err, "MessageStorage.Create")
+		}
+	}
+
+	return &message, nil
+}
@@ -0,0 +1,145 @@
+package storage
+
+import (
+	"context"
+	"github.com/georgysavva/scany/v2/pgxscan"
+	"github.com/jackc/pgx/v5"
+	"github.com/petrkoval/social-network-back/internal/domain"
+	"github.com/petrkoval/social-network-back/pkg/pagination"
+	"github.com/pkg/errors"
+)
+
+// notificationActorsShown is how many actors of a group are returned by name.
+const notificationActorsShown = 3
+
+type NotificationStorage struct {
+	client Client
+}
+
+func NewNotificationStorage(client Client) *NotificationStorage {
+	return &NotificationStorage{client: client}
+}
+
+// Create stores a notification. Repeating the same action, e.g. liking a post again
+// after unliking it, brings the existing notification back to the top as unread.
+func (s *NotificationStorage) Create(ctx context.Context, dto domain.CreateNotificationDTO) error {
+	var (
+		err   error
+		query = `
+			INSERT INTO notifications (user_id, actor_id, type, subject_id) VALUES ($1, $2, $3, $4)
+			ON CONFLICT (user_id, actor_id, type, subject_id) DO UPDATE SET created_at = now(), read_at = NULL`
+	)
+
+	_, err = s.client.Exec(ctx, query, dto.UserID, dto.ActorID, dto.Type, dto.SubjectID)
+	if err != nil {
+		return errors.Wrap(err, "NotificationStorage.Create")
+	}
+
+	return nil
+}
+
+// FindByUserID returns up to limit notification groups of the user, most recent first.
+// With unreadOnly, read notifications are left out of the groups entirely.
+func (s *NotificationStorage) FindByUserID(
+	ctx context.Context,
+	userID string,
+	unreadOnly bool,
+	cursor *pagination.Cursor,
+	limit int,
+) ([]*domain.Notification, error) {
+	var (
+		notifications = make([]*domain.Notification, 0)
+		err           error
+		query         = `
+			WITH groups AS (
+				SELECT n.type,
+					   n.subject_id,
+					   n.type || ':' || n.subject_id::text AS key,
+					   max(n.created_at) AS created_at,
+					   (array_agg(n.notification_id ORDER BY n.created_at DESC, n.notification_id DESC))[1] AS latest_id,
+					   (array_agg(n.actor_id ORDER BY n.created_at DESC, n.notification_id DESC))[1:$6] AS actor_ids,
+					   count(*) AS actor_count,
+					   bool_or(n.read_at IS NULL) AS unread
+				FROM notifications n
+				WHERE n.user_id = $1 AND (NOT $2 OR n.read_at IS NULL)
+				GROUP BY n.type, n.subject_id
+			)
+			SELECT g.key,
+				   g.type,
+				   g.subject_id,
+				   g.created_at,
+				   g.latest_id,
+				   g.actor_count,
+				   g.unread,
+				   (SELECT json_agg(json_build_object('id', u.user_id, 'username', u.username) ORDER BY a.ord)
+					FROM unnest(g.actor_ids) WITH ORDINALITY AS a(user_id, ord)
+					JOIN users u ON u.user_id = a.user_id) AS actors
+			FROM groups g
+			WHERE $3::timestamp IS NULL OR (g.created_at, g.latest_id) < ($3, $4::uuid)
+			ORDER BY g.created_at DESC, g.latest_id DESC
+			LIMIT $5`
+	)
+
+	createdAt, id := cursorArgs(cursor)
+
+	err = pgxscan.Select(ctx, s.client, &notifications, query,
+		userID, unreadOnly, createdAt, id, limit, notificationActorsShown)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, errors.Wrap(err, "NotificationStorage.FindByUserID")
+	}
+
+	return notifications, nil
+}
+
+// MarkRead marks the notifications of the groups with the given keys as read.
+func (s *NotificationStorage) MarkRead(ctx context.Context, userID string, keys []string) error {
+	var (
+		err   error
+		query = `
+			UPDATE notifications SET read_at = now()
+			WHERE user_id = $1
+			  AND read_at IS NULL
+			  AND type || ':' || subject_id::text = ANY($2::text[])`
+	)
+
+	_, err = s.client.Exec(ctx, query, userID, keys)
+	if err != nil {
+		return errors.Wrap(err, "NotificationStorage.MarkRead")
+	}
+
+	return nil
+}
+
+// MarkAllRead marks every notification of the user as read.
+func (s *NotificationStorage) MarkAllRead(ctx context.Context, userID string) error {
+	var (
+		err   error
+		query = `UPDATE notifications SET read_at = now() WHERE user_id = $1 AND read_at IS NULL`
+	)
+
+	_, err = s.client.Exec(ctx, query, userID)
+	if err != nil {
+		return errors.Wrap(err, "NotificationStorage.MarkAllRead")
+	}
+
+	return nil
+}
+
+// CountUnread returns the number of notification groups with unread notifications.
+func (s *NotificationStorage) CountUnread(ctx context.Context, userID string) (int, error) {
+	var (
+		count int
+		err   error
+		query = `
+			SELECT count(DISTINCT (type, subject_id))
+			FROM notifications
+			WHERE user_id = $1 AND read_at IS NULL`
+	)
+
+	err = pgxscan.Get(ctx, s.client, &count, query, userID)
+	if err != nil {
+		return 0, errors.Wrap(err, "NotificationStorage.CountUnread")
+	}
+
+	return count, nil
+}
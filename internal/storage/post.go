@@ -0,0 +1,256 @@
+package storage
+
+import (
+	"context"
+	"github.com/georgysavva/scany/v2/pgxscan"
+	"github.com/jackc/pgx/v5"
+	"github.com/petrkoval/social-network-back/internal/domain"
+	"github.com/petrkoval/social-network-back/pkg/pagination"
+	"github.com/pkg/errors"
+)
+
+// postColumns selects a post aliased as p. The viewer id must always be
+// passed as $1 so that liked_by_me can be computed; anonymous viewers pass NULL.
+const postColumns = `
+	p.post_id,
+	p.user_id,
+	p.channel_id,
+	p.created_at,
+	coalesce(p.content, '') AS content,
+	p.entities,
+	p.media_ids,
+	(SELECT coalesce(json_agg(json_build_object(
+				'id', m.media_id,
+				'width', m.width,
+				'height', m.height,
+				'blurhash', m.blurhash,
+				'variants', (SELECT coalesce(json_agg(v.name ORDER BY v.width), '[]')
+							 FROM media_variants v WHERE v.media_id = m.media_id)
+			) ORDER BY pm.ord), '[]')
+	 FROM unnest(p.media_ids) WITH ORDINALITY AS pm(media_id, ord)
+	 JOIN media m ON m.media_id = pm.media_id) AS media,
+	(SELECT count(*) FROM likes l WHERE l.post_id = p.post_id) AS like_count,
+	exists(SELECT 1 FROM likes l WHERE l.post_id = p.post_id AND l.user_id = $1) AS liked_by_me,
+	(SELECT count(*) FROM comments c WHERE c.post_id = p.post_id AND c.deleted_at IS NULL) AS comment_count`
+
+type PostStorage struct {
+	client Client
+}
+
+func NewPostStorage(client Client) *PostStorage {
+	return &PostStorage{client: client}
+}
+
+func (s *PostStorage) FindByID(ctx context.Context, viewerID, id string) (*domain.Post, error) {
+	var (
+		post  domain.Post
+		err   error
+		query = `SELECT ` + postColumns + ` FROM posts p WHERE p.post_id = $2`
+	)
+
+	err = pgxscan.Get(ctx, s.client, &post, query, nullable(viewerID), id)
+	if err != nil {
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			return nil, errors.Wrap(NotFoundPostErr, "PostStorage.FindByID")
+		default:
+			return nil, errors.Wrap(err, "PostStorage.FindByID")
+		}
+	}
+
+	return &post, nil
+}
+
+// FindByUserID returns up to limit posts of the user, newest first, after the cursor.
+func (s *PostStorage) FindByUserID(
+	ctx context.Context,
+	viewerID, userID string,
+	cursor *pagination.Cursor,
+	limit int,
+) ([]*domain.Post, error) {
+	var (
+		posts = make([]*domain.Post, 0)
+		err   error
+		query = `
+			SELECT ` + postColumns + `
+			FROM posts p
+			WHERE p.user_id = $2
+			  AND ($3::timestamp IS NULL OR (p.created_at, p.post_id) < ($3, $4::uuid))
+			ORDER BY p.created_at DESC, p.post_id DESC
+			LIMIT $5`
+	)
+
+	createdAt, id := cursorArgs(cursor)
+
+	err = pgxscan.Select(ctx, s.client, &posts, query, nullable(viewerID), userID, createdAt, id, limit)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, errors.Wrap(err, "PostStorage.FindByUserID")
+	}
+
+	return posts, nil
+}
+
+// FindByChannelID returns up to limit posts of the channel, newest first, after the cursor.
+func (s *PostStorage) FindByChannelID(
+	ctx context.Context,
+	viewerID, channelID string,
+	cursor *pagination.Cursor,
+	limit int,
+) ([]*domain.Post, error) {
+	var (
+		posts = make([]*domain.Post, 0)
+		err   error
+		query = `
+			SELECT ` + postColumns + `
+			FROM posts p
+			WHERE p.channel_id = $2
+			  AND ($3::timestamp IS NULL OR (p.created_at, p.post_id) < ($3, $4::uuid))
+			ORDER BY p.created_at DESC, p.post_id DESC
+			LIMIT $5`
+	)
+
+	createdAt, id := cursorArgs(cursor)
+
+	err = pgxscan.Select(ctx, s.client, &posts, query, nullable(viewerID), channelID, createdAt, id, limit)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, errors.Wrap(err, "PostStorage.FindByChannelID")
+	}
+
+	return posts, nil
+}
+
+// FindByHashtag returns up to limit posts tagged with the hashtag, newest first, after the cursor.
+func (s *PostStorage) FindByHashtag(
+	ctx context.Context,
+	viewerID, hashtag string,
+	cursor *pagination.Cursor,
+	limit int,
+) ([]*domain.Post, error) {
+	var (
+		posts = make([]*domain.Post, 0)
+		err   error
+		query = `
+			SELECT ` + postColumns + `
+			FROM hashtags h
+			JOIN post_hashtags ph ON ph.hashtag_id = h.hashtag_id
+			JOIN posts p ON p.post_id = ph.post_id
+			WHERE h.name = $2
+			  AND ($3::timestamp IS NULL OR (ph.created_at, ph.post_id) < ($3, $4::uuid))
+			ORDER BY ph.created_at DESC, ph.post_id DESC
+			LIMIT $5`
+	)
+
+	createdAt, id := cursorArgs(cursor)
+
+	err = pgxscan.Select(ctx, s.client, &posts, query, nullable(viewerID), hashtag, createdAt, id, limit)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, errors.Wrap(err, "PostStorage.FindByHashtag")
+	}
+
+	return posts, nil
+}
+
+func (s *PostStorage) Create(ctx context.Context, dto domain.CreatePostDTO) (*domain.Post, error) {
+	var (
+		post  domain.Post
+		query = `
+			WITH p AS (
+				INSERT INTO posts (user_id, channel_id, content, media_ids, entities)
+				VALUES ($1, $2, $3, coalesce($4::uuid[], '{}'), $5)
+				RETURNING *
+			), h AS (
+				INSERT INTO hashtags (name) SELECT unnest($6::text[])
+				ON CONFLICT (name) DO UPDATE SET name = excluded.name
+				RETURNING hashtag_id
+			), ph AS (
+				INSERT INTO post_hashtags (post_id, hashtag_id, created_at)
+				SELECT p.post_id, h.hashtag_id, p.created_at FROM p, h
+			), mn AS (
+				INSERT INTO mentions (post_id, user_id)
+				SELECT p.post_id, unnest($7::uuid[]) FROM p
+			)
+			SELECT ` + postColumns + ` FROM p`
+	)
+
+	hashtags, mentions := entityArgs(dto.Entities)
+
+	rows, err := s.client.Query(ctx, query,
+		dto.UserID, dto.ChannelID, dto.Content, dto.MediaIDs, entitiesArg(dto.Entities), hashtags, mentions)
+	if err != nil {
+		return nil, errors.Wrap(err, "PostStorage.Create")
+	}
+	defer rows.Close()
+
+	err = pgxscan.ScanOne(&post, rows)
+	if err != nil {
+		return nil, errors.Wrap(err, "PostStorage.Create")
+	}
+
+	return &post, nil
+}
+
+func (s *PostStorage) Update(ctx context.Context, viewerID, id string, dto domain.UpdatePostDTO) (*domain.Post, error) {
+	var (
+		post  domain.Post
+		query = `
+			WITH p AS (
+				UPDATE posts SET content = $2, media_ids = coalesce($3::uuid[], '{}'), entities = $4
+				WHERE post_id = $5
+				RETURNING *
+			), h AS (
+				INSERT INTO hashtags (name) SELECT unnest($6::text[])
+				ON CONFLICT (name) DO UPDATE SET name = excluded.name
+				RETURNING hashtag_id
+			), dh AS (
+				DELETE FROM post_hashtags ph USING p
+				WHERE ph.post_id = p.post_id AND ph.hashtag_id NOT IN (SELECT hashtag_id FROM h)
+			), ph AS (
+				INSERT INTO post_hashtags (post_id, hashtag_id, created_at)
+				SELECT p.post_id, h.hashtag_id, p.created_at FROM p, h
+				ON CONFLICT DO NOTHING
+			), dm AS (
+				DELETE FROM mentions m USING p
+				WHERE m.post_id = p.post_id AND m.comment_id IS NULL AND m.user_id <> ALL ($7::uuid[])
+			), mn AS (
+				INSERT INTO mentions (post_id, user_id)
+				SELECT p.post_id, unnest($7::uuid[]) FROM p
+				ON CONFLICT (post_id, user_id) WHERE comment_id IS NULL DO NOTHING
+			)
+			SELECT ` + postColumns + ` FROM p`
+	)
+
+	hashtags, mentions := entityArgs(dto.Entities)
+
+	rows, err := s.client.Query(ctx, query,
+		nullable(viewerID), dto.Content, dto.MediaIDs, entitiesArg(dto.Entities), id, hashtags, mentions)
+	if err != nil {
+		return nil, errors.Wrap(err, "PostStorage.Update")
+	}
+	defer rows.Close()
+
+	err = pgxscan.ScanOne(&post, rows)
+	if err != nil {
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			return nil, errors.Wrap(NotFoundPostErr, "PostStorage.Update")
+		default:
+			return nil, errors.Wrap(err, "PostStorage.Update")
+		}
+	}
+
+	return &post, nil
+}
+
+func (s *PostStorage) Delete(ctx context.Context, id string) error {
+	var (
+		err   error
+		query = `DELETE FROM posts WHERE post_id = $1`
+	)
+
+	_, err = s.client.Exec(ctx, query, id)
+	if err != nil {
+		return errors.Wrap(err, "PostStorage.Delete")
+	}
+
+	return nil
+}
@@ -0,0 +1,110 @@
+package storage
+
+import (
+	"context"
+	"github.com/georgysavva/scany/v2/pgxscan"
+	"github.com/jackc/pgx/v5"
+	"github.com/petrkoval/social-network-back/internal/domain"
+	"github.com/pkg/errors"
+	"strings"
+	"unicode"
+)
+
+// SearchStorage runs ranked full-text queries over the generated search_vector columns.
+type SearchStorage struct {
+	client Client
+}
+
+func NewSearchStorage(client Client) *SearchStorage {
+	return &SearchStorage{client: client}
+}
+
+func (s *SearchStorage) SearchUsers(ctx context.Context, text string, limit int) ([]*domain.User, error) {
+	var (
+		users = make([]*domain.User, 0)
+		err   error
+		query = `
+			SELECT ` + profileColumns + `
+			FROM users u, to_tsquery('simple', $1) q
+			WHERE u.search_vector @@ q
+			ORDER BY ts_rank(u.search_vector, q) DESC, u.created_at DESC, u.user_id DESC
+			LIMIT $2`
+	)
+
+	tsquery := prefixQuery(text)
+	if tsquery == "" {
+		return users, nil
+	}
+
+	err = pgxscan.Select(ctx, s.client, &users, query, tsquery, limit)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, errors.Wrap(err, "SearchStorage.SearchUsers")
+	}
+
+	return users, nil
+}
+
+func (s *SearchStorage) SearchChannels(ctx context.Context, text string, limit int) ([]*domain.Channel, error) {
+	var (
+		channels = make([]*domain.Channel, 0)
+		err      error
+		query    = `
+			SELECT ` + channelColumns + `
+			FROM channels c, to_tsquery('simple', $1) q
+			WHERE c.search_vector @@ q
+			ORDER BY ts_rank(c.search_vector, q) DESC, c.created_at DESC, c.channel_id DESC
+			LIMIT $2`
+	)
+
+	tsquery := prefixQuery(text)
+	if tsquery == "" {
+		return channels, nil
+	}
+
+	err = pgxscan.Select(ctx, s.client, &channels, query, tsquery, limit)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, errors.Wrap(err, "SearchStorage.SearchChannels")
+	}
+
+	return channels, nil
+}
+
+func (s *SearchStorage) SearchPosts(ctx context.Context, viewerID, text string, limit int) ([]*domain.Post, error) {
+	var (
+		posts = make([]*domain.Post, 0)
+		err   error
+		query = `
+			SELECT ` + postColumns + `
+			FROM posts p, to_tsquery('simple', $2) q
+			WHERE p.search_vector @@ q
+			ORDER BY ts_rank(p.search_vector, q) DESC, p.created_at DESC, p.post_id DESC
+			LIMIT $3`
+	)
+
+	tsquery := prefixQuery(text)
+	if tsquery == "" {
+		return posts, nil
+	}
+
+	err = pgxscan.Select(ctx, s.client, &posts, query, nullable(viewerID), tsquery, limit)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, errors.Wrap(err, "SearchStorage.SearchPosts")
+	}
+
+	return posts, nil
+}
+
+// prefixQuery turns free text into a tsquery requiring every word, each of them
+// matched as a prefix so that partially typed words already find results. Anything
+// but letters and digits is dropped, which keeps tsquery syntax out of user input.
+func prefixQuery(text string) string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	for i, word := range words {
+		words[i] = word + ":*"
+	}
+
+	return strings.Join(words, " & ")
+}
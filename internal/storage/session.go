@@ -0,0 +1,202 @@
+package storage
+
+import (
+	"context"
+	"github.com/georgysavva/scany/v2/pgxscan"
+	"github.com/jackc/pgx/v5"
+	"github.com/petrkoval/social-network-back/internal/domain"
+	"github.com/pkg/errors"
+	"time"
+)
+
+type SessionStorage struct {
+	client Client
+}
+
+func NewSessionStorage(client Client) *SessionStorage {
+	return &SessionStorage{client: client}
+}
+
+// FindToken looks a refresh token up by its hash, whether it is current or already rotated.
+func (s *SessionStorage) FindToken(ctx context.Context, tokenHash string) (*domain.RefreshToken, error) {
+	var (
+		query = `
+			SELECT t.token_hash, t.family_id, s.user_id, t.created_at, t.rotated_at
+			FROM refresh_tokens t
+					 JOIN sessions s ON s.session_id = t.family_id
+			WHERE t.token_hash = $1
+			  AND s.expires_at > now();`
+		entity = &domain.RefreshToken{}
+		err    error
+	)
+
+	err = pgxscan.Get(ctx, s.client, entity, query, tokenHash)
+	if err != nil {
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			return nil, errors.Wrap(NotFoundTokenErr, "SessionStorage.FindToken")
+		default:
+			return nil, errors.Wrap(err, "SessionStorage.FindToken")
+		}
+	}
+
+	return entity, nil
+}
+
+// IsActive reports whether the session exists and has not expired.
+func (s *SessionStorage) IsActive(ctx context.Context, id string) (bool, error) {
+	var (
+		query  = `SELECT EXISTS (SELECT 1 FROM sessions WHERE session_id = $1 AND expires_at > now());`
+		active bool
+		err    error
+	)
+
+	err = pgxscan.Get(ctx, s.client, &active, query, id)
+	if err != nil {
+		return false, errors.Wrap(err, "SessionStorage.IsActive")
+	}
+
+	return active, nil
+}
+
+func (s *SessionStorage) FindByUserID(ctx context.Context, userID string) ([]*domain.Session, error) {
+	var (
+		query    = `SELECT * FROM sessions WHERE user_id = $1 AND expires_at > now() ORDER BY last_used_at DESC;`
+		sessions = make([]*domain.Session, 0)
+		err      error
+	)
+
+	err = pgxscan.Select(ctx, s.client, &sessions, query, userID)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, errors.Wrap(err, "SessionStorage.FindByUserID")
+	}
+
+	return sessions, nil
+}
+
+// Create opens a session together with the first refresh token of its family.
+func (s *SessionStorage) Create(ctx context.Context, session domain.Session, tokenHash string) error {
+	var (
+		query = `
+			WITH s AS (
+				INSERT INTO sessions (session_id, user_id, user_agent, ip, expires_at)
+				VALUES ($1, $2, $3, $4, $5)
+				RETURNING session_id
+			)
+			INSERT INTO refresh_tokens (token_hash, family_id)
+			SELECT $6, session_id FROM s;`
+		err error
+	)
+
+	_, err = s.client.Exec(ctx, query,
+		session.ID, session.UserID, session.UserAgent, session.IP, session.ExpiresAt, tokenHash)
+	if err != nil {
+		return errors.Wrap(err, "SessionStorage.Create")
+	}
+
+	return nil
+}
+
+// Rotate marks the presented token as used and issues its successor in one statement,
+// so two concurrent refreshes with the same token cannot both succeed. It returns
+// NotFoundTokenErr when the token was already rotated. The session is extended by ttl,
+// and rotated tokens older than ttl are pruned since they have expired anyway.
+func (s *SessionStorage) Rotate(
+	ctx context.Context,
+	oldHash, newHash string,
+	ttl time.Duration,
+	client domain.ClientInfo,
+) error {
+	var (
+		query = `
+			WITH used AS (
+				UPDATE refresh_tokens SET rotated_at = now()
+				WHERE token_hash = $1 AND rotated_at IS NULL
+				RETURNING family_id
+			), issued AS (
+				INSERT INTO refresh_tokens (token_hash, family_id)
+				SELECT $2, family_id FROM used
+				RETURNING family_id
+			), pruned AS (
+				DELETE FROM refresh_tokens
+				WHERE family_id IN (SELECT family_id FROM used)
+				  AND rotated_at < now() - $3::interval
+			)
+			UPDATE sessions
+			SET expires_at = now() + $3::interval, user_agent = $4, ip = $5, last_used_at = now()
+			WHERE session_id IN (SELECT family_id FROM issued);`
+		err error
+	)
+
+	tag, err := s.client.Exec(ctx, query, oldHash, newHash, ttl, client.UserAgent, client.IP)
+	if err != nil {
+		return errors.Wrap(err, "SessionStorage.Rotate")
+	}
+
+	if tag.RowsAffected() == 0 {
+		return errors.Wrap(NotFoundTokenErr, "SessionStorage.Rotate")
+	}
+
+	return nil
+}
+
+func (s *SessionStorage) Delete(ctx context.Context, userID, id string) error {
+	var (
+		query = `DELETE FROM sessions WHERE session_id = $1 AND user_id = $2;`
+		err   error
+	)
+
+	tag, err := s.client.Exec(ctx, query, id, userID)
+	if err != nil {
+		return errors.Wrap(err, "SessionStorage.Delete")
+	}
+
+	if tag.RowsAffected() == 0 {
+		return errors.Wrap(NotFoundSessionErr, "SessionStorage.Delete")
+	}
+
+	return nil
+}
+
+func (s *SessionStorage) DeleteByToken(ctx context.Context, tokenHash string) error {
+	var (
+		query = `DELETE FROM sessions WHERE session_id = (SELECT family_id FROM refresh_tokens WHERE token_hash = $1);`
+		err   error
+	)
+
+	_, err = s.client.Exec(ctx, query, tokenHash)
+	if err != nil {
+		return errors.Wrap(err, "SessionStorage.DeleteByToken")
+	}
+
+	return nil
+}
+
+func (s *SessionStorage) DeleteByUserID(ctx context.Context, userID string) error {
+	var (
+		query = `DELETE FROM sessions WHERE user_id = $1;`
+		err   error
+	)
+
+	_, err = s.client.Exec(ctx, query, userID)
+	if err != nil {
+		return errors.Wrap(err, "SessionStorage.DeleteByUserID")
+	}
+
+	return nil
+}
+
+// DeleteOthers ends every session of the user except keepID. An empty keepID ends them all.
+func (s *SessionStorage) DeleteOthers(ctx context.Context, userID, keepID string) error {
+	var (
+		query = `DELETE FROM sessions WHERE user_id = $1 AND session_id IS DISTINCT FROM $2::uuid;`
+		err   error
+	)
+
+	_, err = s.client.Exec(ctx, query, userID, nullable(keepID))
+	if err != nil {
+		return errors.Wrap(err, "SessionStorage.DeleteOthers")
+	}
+
+	return nil
+}
@@ -0,0 +1,99 @@
+package storage
+
+import (
+	"context"
+	"github.com/georgysavva/scany/v2/pgxscan"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/petrkoval/social-network-back/internal/domain"
+	"github.com/petrkoval/social-network-back/pkg/pagination"
+	"github.com/pkg/errors"
+)
+
+type SubscriptionStorage struct {
+	client Client
+}
+
+func NewSubscriptionStorage(client Client) *SubscriptionStorage {
+	return &SubscriptionStorage{client: client}
+}
+
+// Create is idempotent: subscribing to an already subscribed channel is a no-op.
+func (s *SubscriptionStorage) Create(ctx context.Context, userID, channelID string) error {
+	var (
+		err   error
+		query = `INSERT INTO channel_subscriptions (user_id, channel_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`
+	)
+
+	_, err = s.client.Exec(ctx, query, userID, channelID)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == foreignKeyViolationCode {
+			return errors.Wrap(NotFoundChannelErr, "SubscriptionStorage.Create")
+		}
+
+		return errors.Wrap(err, "SubscriptionStorage.Create")
+	}
+
+	return nil
+}
+
+func (s *SubscriptionStorage) Delete(ctx context.Context, userID, channelID string) error {
+	var (
+		err   error
+		query = `DELETE FROM channel_subscriptions WHERE user_id = $1 AND channel_id = $2`
+	)
+
+	_, err = s.client.Exec(ctx, query, userID, channelID)
+	if err != nil {
+		return errors.Wrap(err, "SubscriptionStorage.Delete")
+	}
+
+	return nil
+}
+
+// FindSubscribers returns up to limit subscribers of the channel, newest first, after the cursor.
+func (s *SubscriptionStorage) FindSubscribers(
+	ctx context.Context,
+	channelID string,
+	cursor *pagination.Cursor,
+	limit int,
+) ([]*domain.Subscriber, error) {
+	var (
+		subscribers = make([]*domain.Subscriber, 0)
+		err         error
+		query       = `
+			SELECT ` + profileColumns + `, cs.created_at AS subscribed_at
+			FROM channel_subscriptions cs
+					 JOIN users u ON u.user_id = cs.user_id
+			WHERE cs.channel_id = $1
+			  AND ($2::timestamp IS NULL OR (cs.created_at, cs.user_id) < ($2, $3::uuid))
+			ORDER BY cs.created_at DESC, cs.user_id DESC
+			LIMIT $4`
+	)
+
+	createdAt, id := cursorArgs(cursor)
+
+	err = pgxscan.Select(ctx, s.client, &subscribers, query, channelID, createdAt, id, limit)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, errors.Wrap(err, "SubscriptionStorage.FindSubscribers")
+	}
+
+	return subscribers, nil
+}
+
+// FilterSubscribers returns those of userIDs who are subscribed to the channel.
+func (s *SubscriptionStorage) FilterSubscribers(ctx context.Context, channelID string, userIDs []string) ([]string, error) {
+	var (
+		subscribers = make([]string, 0)
+		err         error
+		query       = `SELECT user_id FROM channel_subscriptions WHERE channel_id = $1 AND user_id = ANY($2::uuid[])`
+	)
+
+	err = pgxscan.Select(ctx, s.client, &subscribers, query, channelID, userIDs)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, errors.Wrap(err, "SubscriptionStorage.FilterSubscribers")
+	}
+
+	return subscribers, nil
+}
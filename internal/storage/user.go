@@ -4,11 +4,22 @@ import (
 	"context"
 	"github.com/georgysavva/scany/v2/pgxscan"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/petrkoval/social-network-back/internal/domain"
 	"github.com/pkg/errors"
 )
 
+// profileColumns selects the public part of a user aliased as u.
+const profileColumns = `
+	u.user_id,
+	u.username,
+	u.created_at,
+	coalesce(u.account_description, '') AS account_description,
+	u.avatar_id,
+	(SELECT count(*) FROM follows fc WHERE fc.followee_id = u.user_id) AS follower_count,
+	(SELECT count(*) FROM follows fc WHERE fc.follower_id = u.user_id) AS following_count`
+
 type UserStorage struct {
 	client Client
 }
@@ -33,6 +44,11 @@ func (s *UserStorage) Create(ctx context.Context, dto domain.CreateUserDTO) (*do
 
 	err = pgxscan.ScanOne(entity, rows)
 	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == uniqueViolationCode {
+			return nil, errors.Wrap(DuplicateUsernameErr, "UserStorage.Create")
+		}
+
 		return nil, errors.Wrap(err, "UserStorage.Create")
 	}
 
@@ -46,7 +62,10 @@ func (s *UserStorage) FindByID(ctx context.Context, userID string) (*domain.User
 				   username,
 				   password,
 				   created_at,
-				   coalesce(account_description, '') as account_description
+				   coalesce(account_description, '') as account_description,
+				   avatar_id,
+				   (SELECT count(*) FROM follows WHERE followee_id = users.user_id) AS follower_count,
+				   (SELECT count(*) FROM follows WHERE follower_id = users.user_id) AS following_count
 			FROM users
 			WHERE user_id = $1;`
 		entity = &domain.User{}
@@ -73,7 +92,10 @@ func (s *UserStorage) FindByUsername(ctx context.Context, username string) (*dom
 				   username,
 				   password,
 				   created_at,
-				   coalesce(account_description, '') as account_description
+				   coalesce(account_description, '') as account_description,
+				   avatar_id,
+				   (SELECT count(*) FROM follows WHERE followee_id = users.user_id) AS follower_count,
+				   (SELECT count(*) FROM follows WHERE follower_id = users.user_id) AS following_count
 			FROM users
 			WHERE username = $1;`
 		entity = &domain.User{}
@@ -93,27 +115,59 @@ func (s *UserStorage) FindByUsername(ctx context.Context, username string) (*dom
 	return entity, nil
 }
 
-func (s *UserStorage) UpdateUsername(ctx context.Context, userID string, username string) (*domain.User, error) {
+// FindByUsernames returns the public profiles of the users with the given usernames; unknown ones are skipped.
+func (s *UserStorage) FindByUsernames(ctx context.Context, usernames []string) ([]*domain.User, error) {
+	var (
+		users = make([]*domain.User, 0)
+		err   error
+		query = `SELECT ` + profileColumns + ` FROM users u WHERE u.username = ANY($1)`
+	)
+
+	err = pgxscan.Select(ctx, s.client, &users, query, usernames)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, errors.Wrap(err, "UserStorage.FindByUsernames")
+	}
+
+	return users, nil
+}
+
+// Update changes the profile fields set in dto in a single statement and leaves the
+// nil ones as they are. An empty description or avatar id clears it.
+func (s *UserStorage) Update(ctx context.Context, userID string, dto domain.UpdateUserDTO) (*domain.User, error) {
 	var (
-		query  = `UPDATE users SET username = $1 WHERE user_id = $2 RETURNING *;`
+		query = `
+			UPDATE users
+			SET username            = coalesce($1, username),
+				account_description = CASE WHEN $2::text IS NULL THEN account_description ELSE nullif($2, '') END,
+				avatar_id           = CASE WHEN $3::text IS NULL THEN avatar_id ELSE nullif($3, '')::uuid END
+			WHERE user_id = $4
+			RETURNING user_id,
+					  username,
+					  password,
+					  created_at,
+					  coalesce(account_description, '') as account_description,
+					  avatar_id;`
 		entity = &domain.User{}
 		rows   pgx.Rows
 		err    error
 	)
 
-	rows, err = s.client.Query(ctx, query, username, userID)
+	rows, err = s.client.Query(ctx, query, dto.Username, dto.AccountDescription, dto.AvatarID, userID)
 	if err != nil {
-		return nil, errors.Wrap(err, "UserStorage.UpdateUsername")
+		return nil, errors.Wrap(err, "UserStorage.Update")
 	}
 	defer rows.Close()
 
-	err = pgxscan.ScanOne(&entity, rows)
+	err = pgxscan.ScanOne(entity, rows)
 	if err != nil {
+		var pgErr *pgconn.PgError
 		switch {
 		case errors.Is(err, pgx.ErrNoRows):
-			return nil, errors.Wrap(NotFoundUserErr, "UserStorage.UpdateUsername")
+			return nil, errors.Wrap(NotFoundUserErr, "UserStorage.Update")
+		case errors.As(err, &pgErr) && pgErr.Code == uniqueViolationCode:
+			return nil, errors.Wrap(DuplicateUsernameErr, "UserStorage.Update")
 		default:
-			return nil, errors.Wrap(err, "UserStorage.UpdateUsername")
+			return nil, errors.Wrap(err, "UserStorage.Update")
 		}
 	}
 
@@ -122,7 +176,14 @@ func (s *UserStorage) UpdateUsername(ctx context.Context, userID string, usernam
 
 func (s *UserStorage) UpdatePassword(ctx context.Context, userID string, password string) (*domain.User, error) {
 	var (
-		query  = `UPDATE users SET password = $1 WHERE user_id = $2 RETURNING *;`
+		query = `
+			UPDATE users SET password = $1 WHERE user_id = $2
+			RETURNING user_id,
+					  username,
+					  password,
+					  created_at,
+					  coalesce(account_description, '') as account_description,
+					  avatar_id;`
 		entity = &domain.User{}
 		rows   pgx.Rows
 		err    error
@@ -134,7 +195,7 @@ func (s *UserStorage) UpdatePassword(ctx context.Context, userID string, passwor
 	}
 	defer rows.Close()
 
-	err = pgxscan.ScanOne(&entity, rows)
+	err = pgxscan.ScanOne(entity, rows)
 	if err != nil {
 		switch {
 		case errors.Is(err, pgx.ErrNoRows):
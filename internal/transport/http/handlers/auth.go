@@ -3,13 +3,13 @@ package handlers
 import (
 	"context"
 	"encoding/json"
-	"errors"
 	"github.com/go-chi/chi/v5"
 	"github.com/petrkoval/social-network-back/internal/domain"
 	"github.com/petrkoval/social-network-back/internal/services"
-	"github.com/petrkoval/social-network-back/internal/storage"
 	http2 "github.com/petrkoval/social-network-back/internal/transport/http"
+	"github.com/petrkoval/social-network-back/internal/transport/http/problem"
 	"github.com/rs/zerolog"
+	"net"
 	"net/http"
 )
 
@@ -21,10 +21,10 @@ const (
 )
 
 type AuthService interface {
-	Register(ctx context.Context, dto domain.CreateUserDTO) (*services.AuthResponse, error)
-	Login(ctx context.Context, dto domain.CreateUserDTO) (*services.AuthResponse, error)
+	Register(ctx context.Context, dto domain.CreateUserDTO, client domain.ClientInfo) (*services.AuthResponse, error)
+	Login(ctx context.Context, dto domain.LoginDTO, client domain.ClientInfo) (*services.AuthResponse, error)
 	Logout(ctx context.Context, refreshToken string) error
-	Refresh(ctx context.Context, refreshToken string) (*services.AuthResponse, error)
+	Refresh(ctx context.Context, refreshToken string, client domain.ClientInfo) (*services.AuthResponse, error)
 }
 
 type authHandler struct {
@@ -57,20 +57,14 @@ func (h *authHandler) Register(w http.ResponseWriter, r *http.Request) {
 		entity domain.CreateUserDTO
 	)
 
-	_ = json.NewDecoder(r.Body).Decode(&entity)
+	if !decodeJSON(w, r, &entity, h.logger) {
+		return
+	}
 
-	response, err := h.service.Register(r.Context(), entity)
+	response, err := h.service.Register(r.Context(), entity, clientInfo(r))
 	if err != nil {
-		switch {
-		case errors.Is(err, services.UserExistsErr):
-			h.logger.Error().Stack().Err(err).Msg("User already exists")
-			WriteErrorResponse(w, r, err, http.StatusConflict)
-			return
-		default:
-			h.logger.Error().Stack().Err(err).Msg("unhandled error")
-			WriteErrorResponse(w, r, err, http.StatusInternalServerError)
-			return
-		}
+		WriteErrorResponse(w, r, err, h.logger)
+		return
 	}
 
 	cookie := http.Cookie{
@@ -88,26 +82,17 @@ func (h *authHandler) Register(w http.ResponseWriter, r *http.Request) {
 
 func (h *authHandler) Login(w http.ResponseWriter, r *http.Request) {
 	var (
-		entity domain.CreateUserDTO
+		entity domain.LoginDTO
 	)
 
-	_ = json.NewDecoder(r.Body).Decode(&entity)
+	if !decodeJSON(w, r, &entity, h.logger) {
+		return
+	}
 
-	response, err := h.service.Login(r.Context(), entity)
+	response, err := h.service.Login(r.Context(), entity, clientInfo(r))
 	if err != nil {
-		switch {
-		case errors.Is(err, storage.NotFoundUserErr):
-			h.logger.Error().Stack().Err(err).Msg("User not found")
-			WriteErrorResponse(w, r, err, http.StatusNotFound)
-			return
-		case errors.Is(err, services.WrongPasswordErr):
-			WriteErrorResponse(w, r, err, http.StatusNotFound)
-			return
-		default:
-			h.logger.Error().Stack().Err(err).Msg("unhandled error")
-			WriteErrorResponse(w, r, err, http.StatusInternalServerError)
-			return
-		}
+		WriteErrorResponse(w, r, err, h.logger)
+		return
 	}
 
 	cookie := http.Cookie{
@@ -125,22 +110,13 @@ func (h *authHandler) Login(w http.ResponseWriter, r *http.Request) {
 func (h *authHandler) Logout(w http.ResponseWriter, r *http.Request) {
 	refreshToken, err := r.Cookie("refresh_token")
 	if err != nil {
-		switch {
-		case errors.Is(err, http.ErrNoCookie):
-			h.logger.Error().Stack().Err(err).Msg("no refresh_token cookie found")
-			WriteErrorResponse(w, r, err, http.StatusUnauthorized)
-			return
-		default:
-			h.logger.Error().Stack().Err(err).Msg("unhandled error")
-			WriteErrorResponse(w, r, err, http.StatusInternalServerError)
-			return
-		}
+		WriteErrorResponse(w, r, problem.MissingRefreshTokenErr, h.logger)
+		return
 	}
 
 	err = h.service.Logout(r.Context(), refreshToken.Value)
 	if err != nil {
-		h.logger.Error().Stack().Err(err).Msg("unhandled error")
-		WriteErrorResponse(w, r, err, http.StatusInternalServerError)
+		WriteErrorResponse(w, r, err, h.logger)
 		return
 	}
 
@@ -157,38 +133,14 @@ func (h *authHandler) Logout(w http.ResponseWriter, r *http.Request) {
 func (h *authHandler) Refresh(w http.ResponseWriter, r *http.Request) {
 	refreshToken, err := r.Cookie("refresh_token")
 	if err != nil {
-		switch {
-		case errors.Is(err, http.ErrNoCookie):
-			h.logger.Error().Stack().Err(err).Msg("no refresh_token cookie found")
-			WriteErrorResponse(w, r, err, http.StatusUnauthorized)
-			return
-		default:
-			h.logger.Error().Stack().Err(err).Msg("unhandled error")
-			WriteErrorResponse(w, r, err, http.StatusInternalServerError)
-			return
-		}
+		WriteErrorResponse(w, r, problem.MissingRefreshTokenErr, h.logger)
+		return
 	}
 
-	response, err := h.service.Refresh(r.Context(), refreshToken.Value)
+	response, err := h.service.Refresh(r.Context(), refreshToken.Value, clientInfo(r))
 	if err != nil {
-		switch {
-		case errors.Is(err, services.TokenExpiredErr):
-			h.logger.Error().Stack().Err(err).Msg("refresh token expired")
-			WriteErrorResponse(w, r, err, http.StatusUnauthorized)
-			return
-		case errors.Is(err, services.InvalidTokenErr):
-			h.logger.Error().Stack().Err(err).Msg("invalid token")
-			WriteErrorResponse(w, r, err, http.StatusUnauthorized)
-			return
-		case errors.Is(err, storage.NotFoundTokenErr):
-			h.logger.Error().Stack().Err(err).Msg("token not found")
-			WriteErrorResponse(w, r, err, http.StatusUnauthorized)
-			return
-		default:
-			h.logger.Error().Stack().Err(err).Msg("unhandled error")
-			WriteErrorResponse(w, r, err, http.StatusInternalServerError)
-			return
-		}
+		WriteErrorResponse(w, r, err, h.logger)
+		return
 	}
 
 	cookie := http.Cookie{
@@ -202,3 +154,16 @@ func (h *authHandler) Refresh(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusCreated)
 	_ = json.NewEncoder(w).Encode(response)
 }
+
+// clientInfo describes the device the request came from for session bookkeeping.
+func clientInfo(r *http.Request) domain.ClientInfo {
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		ip = r.RemoteAddr
+	}
+
+	return domain.ClientInfo{
+		UserAgent: r.UserAgent(),
+		IP:        ip,
+	}
+}
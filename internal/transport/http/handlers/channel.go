@@ -5,11 +5,10 @@ import (
 	"encoding/json"
 	"github.com/go-chi/chi/v5"
 	"github.com/petrkoval/social-network-back/internal/domain"
-	"github.com/petrkoval/social-network-back/internal/services"
 	"github.com/petrkoval/social-network-back/internal/storage"
 	http2 "github.com/petrkoval/social-network-back/internal/transport/http"
 	"github.com/petrkoval/social-network-back/internal/transport/http/middlewares"
-	"github.com/pkg/errors"
+	"github.com/petrkoval/social-network-back/pkg/pagination"
 	"github.com/rs/zerolog"
 	"net/http"
 )
@@ -18,52 +17,71 @@ const (
 	path           = "/channels"
 	channelUrl     = "/user"
 	channelByIDUrl = "/{id}"
+
+	channelSubscriptionUrl = "/subscription"
+	channelSubscribersUrl  = "/subscribers"
 )
 
 type ChannelService interface {
-	FindAll(ctx context.Context, limit, offset string) ([]*domain.Channel, error)
-	FindByUserID(ctx context.Context, userID string) ([]*domain.Channel, error)
+	FindAll(ctx context.Context, cursor, limit string) (*pagination.Page[*domain.Channel], error)
+	FindByUserID(ctx context.Context, userID, cursor, limit string) (*pagination.Page[*domain.Channel], error)
 	FindByID(ctx context.Context, id string) (*domain.Channel, error)
 	Create(ctx context.Context, dto domain.CreateChannelDTO) (*domain.Channel, error)
-	Update(ctx context.Context, id string, dto domain.UpdateChannelDTO) (*domain.Channel, error)
-	Delete(ctx context.Context, id string) error
+	Update(ctx context.Context, userID, id string, dto domain.UpdateChannelDTO) (*domain.Channel, error)
+	Delete(ctx context.Context, userID, id string) error
+}
+
+type SubscriptionService interface {
+	Subscribe(ctx context.Context, userID, channelID string) error
+	Unsubscribe(ctx context.Context, userID, channelID string) error
+	Subscribers(ctx context.Context, channelID, cursor, limit string) (*pagination.Page[*domain.Subscriber], error)
 }
 
 type tokenService interface {
-	VerifyAccessToken(accessToken string) (*domain.AuthUser, error)
+	VerifyAccessToken(ctx context.Context, accessToken string) (*domain.AuthUser, error)
 }
 
 type channelHandler struct {
-	tokenService tokenService
-	service      ChannelService
-	logger       *zerolog.Logger
-	router       *chi.Mux
+	tokenService        tokenService
+	service             ChannelService
+	subscriptionService SubscriptionService
+	logger              *zerolog.Logger
+	router              *chi.Mux
 }
 
-func NewChannelHandler(s ChannelService, t tokenService, l *zerolog.Logger) Handler {
+func NewChannelHandler(s ChannelService, ss SubscriptionService, t tokenService, l *zerolog.Logger) Handler {
 	r := chi.NewRouter()
 
 	return &channelHandler{
-		tokenService: t,
-		service:      s,
-		logger:       l,
-		router:       r,
+		tokenService:        t,
+		service:             s,
+		subscriptionService: ss,
+		logger:              l,
+		router:              r,
 	}
 }
 
 func (h *channelHandler) MountOn(router *http2.Router) {
-	h.router.Get("/", h.FindAll)
-	h.router.Get(channelUrl, h.FindByUserID)
-
 	authMiddleware := func(next http.Handler) http.Handler {
 		return middlewares.Auth(next, h.tokenService, h.logger)
 	}
+	optionalAuthMiddleware := func(next http.Handler) http.Handler {
+		return middlewares.OptionalAuth(next, h.tokenService, h.logger)
+	}
+
+	h.router.With(optionalAuthMiddleware).Get("/", h.FindAll)
+	h.router.With(optionalAuthMiddleware).Get(channelUrl, h.FindByUserID)
+
+	h.router.With(authMiddleware).Post("/", h.Create)
 
 	h.router.Route(channelByIDUrl, func(r chi.Router) {
-		r.Get("/", h.FindByID)
-		r.With(authMiddleware).Post("/", h.Create)
+		r.Use(middlewares.UUIDParam("id", storage.NotFoundChannelErr))
+		r.With(optionalAuthMiddleware).Get("/", h.FindByID)
 		r.With(authMiddleware).Patch("/", h.Update)
 		r.With(authMiddleware).Delete("/", h.Delete)
+		r.With(authMiddleware).Put(channelSubscriptionUrl, h.Subscribe)
+		r.With(authMiddleware).Delete(channelSubscriptionUrl, h.Unsubscribe)
+		r.Get(channelSubscribersUrl, h.Subscribers)
 	})
 
 	router.Mount(path, h.router)
@@ -72,48 +90,41 @@ func (h *channelHandler) MountOn(router *http2.Router) {
 func (h *channelHandler) FindAll(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	var (
-		query  = r.URL.Query()
-		limit  = query.Get("limit")
-		offset = query.Get("offset")
+		query = r.URL.Query()
 	)
 
-	entities, err := h.service.FindAll(r.Context(), limit, offset)
+	page, err := h.service.FindAll(r.Context(), query.Get("cursor"), query.Get("limit"))
 
 	if err != nil {
-		switch {
-		case errors.Is(err, services.QueryParamParsingErr):
-			WriteErrorResponse(w, r, err, http.StatusBadRequest)
-			return
-		default:
-			WriteErrorResponse(w, r, err, http.StatusInternalServerError)
-			h.logger.Error().Stack().Err(err).Msg("unhandled error")
-			return
-		}
+		WriteErrorResponse(w, r, err, h.logger)
+		return
 	}
 
 	w.WriteHeader(http.StatusOK)
-	_ = json.NewEncoder(w).Encode(entities)
+	_ = json.NewEncoder(w).Encode(page)
 }
 
 func (h *channelHandler) FindByUserID(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	var (
-		userID = r.URL.Query().Get("user_id")
+		query = r.URL.Query()
 	)
 
-	entities, err := h.service.FindByUserID(r.Context(), userID)
+	userID, err := queryID(query, "user_id")
+	if err != nil {
+		WriteErrorResponse(w, r, err, h.logger)
+		return
+	}
+
+	page, err := h.service.FindByUserID(r.Context(), userID, query.Get("cursor"), query.Get("limit"))
 
 	if err != nil {
-		switch {
-		default:
-			WriteErrorResponse(w, r, err, http.StatusInternalServerError)
-			h.logger.Error().Stack().Err(err).Msg("unhandled error")
-			return
-		}
+		WriteErrorResponse(w, r, err, h.logger)
+		return
 	}
 
 	w.WriteHeader(http.StatusOK)
-	_ = json.NewEncoder(w).Encode(entities)
+	_ = json.NewEncoder(w).Encode(page)
 }
 
 func (h *channelHandler) FindByID(w http.ResponseWriter, r *http.Request) {
@@ -125,15 +136,8 @@ func (h *channelHandler) FindByID(w http.ResponseWriter, r *http.Request) {
 	entity, err := h.service.FindByID(r.Context(), id)
 
 	if err != nil {
-		switch {
-		case errors.Is(err, storage.NotFoundChannelErr):
-			WriteErrorResponse(w, r, err, http.StatusNotFound)
-			return
-		default:
-			WriteErrorResponse(w, r, err, http.StatusInternalServerError)
-			h.logger.Error().Stack().Err(err).Msg("unhandled error")
-			return
-		}
+		WriteErrorResponse(w, r, err, h.logger)
+		return
 	}
 
 	w.WriteHeader(http.StatusOK)
@@ -143,20 +147,21 @@ func (h *channelHandler) FindByID(w http.ResponseWriter, r *http.Request) {
 func (h *channelHandler) Create(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	var (
-		userID = chi.URLParam(r, "id")
-		dto    = domain.CreateChannelDTO{UserID: userID}
-		_      = json.NewDecoder(r.Body).Decode(&dto)
+		user, _ = middlewares.UserFromContext(r.Context())
+		dto     = domain.CreateChannelDTO{}
 	)
 
+	if !decodeJSON(w, r, &dto, h.logger) {
+		return
+	}
+
+	dto.UserID = user.ID
+
 	entity, err := h.service.Create(r.Context(), dto)
 
 	if err != nil {
-		switch {
-		default:
-			WriteErrorResponse(w, r, err, http.StatusInternalServerError)
-			h.logger.Error().Stack().Err(err).Msg("unhandled error")
-			return
-		}
+		WriteErrorResponse(w, r, err, h.logger)
+		return
 	}
 
 	w.WriteHeader(http.StatusCreated)
@@ -166,20 +171,20 @@ func (h *channelHandler) Create(w http.ResponseWriter, r *http.Request) {
 func (h *channelHandler) Update(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	var (
-		id  = chi.URLParam(r, "id")
-		dto = domain.UpdateChannelDTO{}
-		_   = json.NewDecoder(r.Body).Decode(&dto)
+		user, _ = middlewares.UserFromContext(r.Context())
+		id      = chi.URLParam(r, "id")
+		dto     = domain.UpdateChannelDTO{}
 	)
 
-	entity, err := h.service.Update(r.Context(), id, dto)
+	if !decodeJSON(w, r, &dto, h.logger) {
+		return
+	}
+
+	entity, err := h.service.Update(r.Context(), user.ID, id, dto)
 
 	if err != nil {
-		switch {
-		default:
-			WriteErrorResponse(w, r, err, http.StatusInternalServerError)
-			h.logger.Error().Stack().Err(err).Msg("unhandled error")
-			return
-		}
+		WriteErrorResponse(w, r, err, h.logger)
+		return
 	}
 
 	w.WriteHeader(http.StatusOK)
@@ -189,19 +194,68 @@ func (h *channelHandler) Update(w http.ResponseWriter, r *http.Request) {
 func (h *channelHandler) Delete(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	var (
-		id = chi.URLParam(r, "id")
+		user, _ = middlewares.UserFromContext(r.Context())
+		id      = chi.URLParam(r, "id")
 	)
 
-	err := h.service.Delete(r.Context(), id)
+	err := h.service.Delete(r.Context(), user.ID, id)
 
 	if err != nil {
-		switch {
-		default:
-			WriteErrorResponse(w, r, err, http.StatusInternalServerError)
-			h.logger.Error().Stack().Err(err).Msg("unhandled error")
-			return
-		}
+		WriteErrorResponse(w, r, err, h.logger)
+		return
 	}
 
 	w.WriteHeader(http.StatusNoContent)
 }
+
+func (h *channelHandler) Subscribe(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	var (
+		user, _ = middlewares.UserFromContext(r.Context())
+		id      = chi.URLParam(r, "id")
+	)
+
+	err := h.subscriptionService.Subscribe(r.Context(), user.ID, id)
+
+	if err != nil {
+		WriteErrorResponse(w, r, err, h.logger)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *channelHandler) Unsubscribe(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	var (
+		user, _ = middlewares.UserFromContext(r.Context())
+		id      = chi.URLParam(r, "id")
+	)
+
+	err := h.subscriptionService.Unsubscribe(r.Context(), user.ID, id)
+
+	if err != nil {
+		WriteErrorResponse(w, r, err, h.logger)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *channelHandler) Subscribers(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	var (
+		id    = chi.URLParam(r, "id")
+		query = r.URL.Query()
+	)
+
+	page, err := h.subscriptionService.Subscribers(r.Context(), id, query.Get("cursor"), query.Get("limit"))
+
+	if err != nil {
+		WriteErrorResponse(w, r, err, h.logger)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(page)
+}
@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/go-chi/chi/v5"
+	"github.com/petrkoval/social-network-back/internal/domain"
+	"github.com/petrkoval/social-network-back/internal/storage"
+	http2 "github.com/petrkoval/social-network-back/internal/transport/http"
+	"github.com/petrkoval/social-network-back/internal/transport/http/middlewares"
+	"github.com/petrkoval/social-network-back/pkg/pagination"
+	"github.com/rs/zerolog"
+	"net/http"
+)
+
+const (
+	commentsPath   = "/comments"
+	commentByIDUrl = "/{id}"
+)
+
+type CommentService interface {
+	FindByPostID(ctx context.Context, postID, cursor, limit string) (*pagination.Page[*domain.Comment], error)
+	Create(ctx context.Context, dto domain.CreateCommentDTO) (*domain.Comment, error)
+	Update(ctx context.Context, userID, id string, dto domain.UpdateCommentDTO) (*domain.Comment, error)
+	Delete(ctx context.Context, userID, id string) error
+}
+
+type commentHandler struct {
+	tokenService tokenService
+	service      CommentService
+	logger       *zerolog.Logger
+	router       *chi.Mux
+}
+
+func NewCommentHandler(s CommentService, t tokenService, l *zerolog.Logger) Handler {
+	r := chi.NewRouter()
+
+	return &commentHandler{
+		tokenService: t,
+		service:      s,
+		logger:       l,
+		router:       r,
+	}
+}
+
+func (h *commentHandler) MountOn(router *http2.Router) {
+	h.router.Use(func(next http.Handler) http.Handler {
+		return middlewares.Auth(next, h.tokenService, h.logger)
+	})
+
+	h.router.Route(commentByIDUrl, func(r chi.Router) {
+		r.Use(middlewares.UUIDParam("id", storage.NotFoundCommentErr))
+		r.Patch("/", h.Update)
+		r.Delete("/", h.Delete)
+	})
+
+	router.Mount(commentsPath, h.router)
+}
+
+func (h *commentHandler) Update(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	var (
+		user, _ = middlewares.UserFromContext(r.Context())
+		id      = chi.URLParam(r, "id")
+		dto     = domain.UpdateCommentDTO{}
+	)
+
+	if !decodeJSON(w, r, &dto, h.logger) {
+		return
+	}
+
+	entity, err := h.service.Update(r.Context(), user.ID, id, dto)
+
+	if err != nil {
+		WriteErrorResponse(w, r, err, h.logger)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(entity)
+}
+
+func (h *commentHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	var (
+		user, _ = middlewares.UserFromContext(r.Context())
+		id      = chi.URLParam(r, "id")
+	)
+
+	err := h.service.Delete(r.Context(), user.ID, id)
+
+	if err != nil {
+		WriteErrorResponse(w, r, err, h.logger)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
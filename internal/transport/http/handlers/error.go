@@ -1,21 +1,18 @@
 package handlers
 
 import (
-	"encoding/json"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/petrkoval/social-network-back/internal/transport/http/problem"
+	"github.com/rs/zerolog"
 	"net/http"
 )
 
-type ErrorMessage struct {
-	StatusCode int    `json:"status_code,omitempty"`
-	Message    string `json:"message,omitempty"`
-	URL        string `json:"url,omitempty"`
-}
+// WriteErrorResponse answers with the problem registered for err. Errors that are not
+// registered are answered with a bare 500 and logged, since they were not expected.
+func WriteErrorResponse(w http.ResponseWriter, r *http.Request, err error, l *zerolog.Logger) {
+	status := problem.Write(w, r, err)
 
-func WriteErrorResponse(w http.ResponseWriter, r *http.Request, err error, statusCode int) {
-	w.WriteHeader(statusCode)
-	_ = json.NewEncoder(w).Encode(ErrorMessage{
-		StatusCode: statusCode,
-		Message:    err.Error(),
-		URL:        r.Host + r.URL.Path,
-	})
+	if status >= http.StatusInternalServerError {
+		l.Error().Stack().Err(err).Str("request_id", middleware.GetReqID(r.Context())).Msg("unhandled error")
+	}
 }
@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/go-chi/chi/v5"
+	"github.com/petrkoval/social-network-back/internal/domain"
+	http2 "github.com/petrkoval/social-network-back/internal/transport/http"
+	"github.com/petrkoval/social-network-back/internal/transport/http/middlewares"
+	"github.com/petrkoval/social-network-back/pkg/pagination"
+	"github.com/rs/zerolog"
+	"net/http"
+)
+
+const (
+	feedPath = "/feed"
+)
+
+type FeedService interface {
+	Feed(ctx context.Context, userID, cursor, limit string) (*pagination.Page[*domain.Post], error)
+}
+
+type feedHandler struct {
+	tokenService tokenService
+	service      FeedService
+	logger       *zerolog.Logger
+	router       *chi.Mux
+}
+
+func NewFeedHandler(s FeedService, t tokenService, l *zerolog.Logger) Handler {
+	r := chi.NewRouter()
+
+	return &feedHandler{
+		tokenService: t,
+		service:      s,
+		logger:       l,
+		router:       r,
+	}
+}
+
+func (h *feedHandler) MountOn(router *http2.Router) {
+	h.router.Use(func(next http.Handler) http.Handler {
+		return middlewares.Auth(next, h.tokenService, h.logger)
+	})
+
+	h.router.Get("/", h.Feed)
+
+	router.Mount(feedPath, h.router)
+}
+
+func (h *feedHandler) Feed(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	var (
+		user, _ = middlewares.UserFromContext(r.Context())
+		query   = r.URL.Query()
+	)
+
+	page, err := h.service.Feed(r.Context(), user.ID, query.Get("cursor"), query.Get("limit"))
+
+	if err != nil {
+		WriteErrorResponse(w, r, err, h.logger)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(page)
+}
@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/go-chi/chi/v5"
+	"github.com/petrkoval/social-network-back/internal/domain"
+	http2 "github.com/petrkoval/social-network-back/internal/transport/http"
+	"github.com/petrkoval/social-network-back/internal/transport/http/middlewares"
+	"github.com/petrkoval/social-network-back/pkg/pagination"
+	"github.com/rs/zerolog"
+	"net/http"
+)
+
+const (
+	hashtagsPath       = "/hashtags"
+	hashtagTrendingUrl = "/trending"
+	hashtagPostsUrl    = "/{tag}/posts"
+)
+
+type HashtagService interface {
+	Trending(ctx context.Context, hours, limit string) ([]*domain.Hashtag, error)
+	Posts(ctx context.Context, viewerID, hashtag, cursor, limit string) (*pagination.Page[*domain.Post], error)
+}
+
+type hashtagHandler struct {
+	tokenService tokenService
+	service      HashtagService
+	logger       *zerolog.Logger
+	router       *chi.Mux
+}
+
+func NewHashtagHandler(s HashtagService, t tokenService, l *zerolog.Logger) Handler {
+	r := chi.NewRouter()
+
+	return &hashtagHandler{
+		tokenService: t,
+		service:      s,
+		logger:       l,
+		router:       r,
+	}
+}
+
+func (h *hashtagHandler) MountOn(router *http2.Router) {
+	h.router.Get(hashtagTrendingUrl, h.Trending)
+	h.router.With(func(next http.Handler) http.Handler {
+		return middlewares.OptionalAuth(next, h.tokenService, h.logger)
+	}).Get(hashtagPostsUrl, h.Posts)
+
+	router.Mount(hashtagsPath, h.router)
+}
+
+// Trending accepts the window size in the "hours" query parameter.
+func (h *hashtagHandler) Trending(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	var (
+		query = r.URL.Query()
+	)
+
+	hashtags, err := h.service.Trending(r.Context(), query.Get("hours"), query.Get("limit"))
+
+	if err != nil {
+		WriteErrorResponse(w, r, err, h.logger)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(hashtags)
+}
+
+func (h *hashtagHandler) Posts(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	var (
+		tag   = chi.URLParam(r, "tag")
+		query = r.URL.Query()
+	)
+
+	page, err := h.service.Posts(r.Context(), viewerID(r), tag, query.Get("cursor"), query.Get("limit"))
+
+	if err != nil {
+		WriteErrorResponse(w, r, err, h.logger)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(page)
+}
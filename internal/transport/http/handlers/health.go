@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	http2 "github.com/petrkoval/social-network-back/internal/transport/http"
+	"github.com/rs/zerolog"
+	"net/http"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+const (
+	livenessUrl  = "/healthz"
+	readinessUrl = "/readyz"
+	versionUrl   = "/version"
+
+	healthCheckTimeout = 2 * time.Second
+)
+
+// HealthChecker is a dependency the service cannot serve requests without. Check
+// returns nil when the dependency is usable.
+type HealthChecker interface {
+	Name() string
+	Check(ctx context.Context) error
+}
+
+type healthResponse struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks,omitempty"`
+}
+
+type versionResponse struct {
+	Version   string `json:"version"`
+	GoVersion string `json:"go_version"`
+	Revision  string `json:"revision,omitempty"`
+	Time      string `json:"time,omitempty"`
+	Modified  bool   `json:"modified,omitempty"`
+}
+
+type healthHandler struct {
+	checkers []HealthChecker
+	logger   *zerolog.Logger
+}
+
+func NewHealthHandler(checkers []HealthChecker, l *zerolog.Logger) Handler {
+	return &healthHandler{
+		checkers: checkers,
+		logger:   l,
+	}
+}
+
+// MountOn registers the probes on the root router itself: they are public and
+// live next to the routes of the auth handler, which is mounted on "/".
+func (h *healthHandler) MountOn(router *http2.Router) {
+	router.Get(livenessUrl, h.Liveness)
+	router.Get(readinessUrl, h.Readiness)
+	router.Get(versionUrl, h.Version)
+}
+
+// Liveness only tells that the process is serving requests; it checks no dependencies,
+// so that an outage of the database does not get the service restarted.
+func (h *healthHandler) Liveness(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(healthResponse{Status: "ok"})
+}
+
+// Readiness runs all checkers concurrently and answers 503 if any of them fails.
+// The errors are only logged, the response just names the failing checks.
+func (h *healthHandler) Readiness(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	ctx, cancel := context.WithTimeout(r.Context(), healthCheckTimeout)
+	defer cancel()
+
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		response = healthResponse{Status: "ok", Checks: make(map[string]string, len(h.checkers))}
+	)
+
+	for _, checker := range h.checkers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			err := checker.Check(ctx)
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			if err != nil {
+				h.logger.Warn().Err(err).Str("check", checker.Name()).Msg("readiness check failed")
+				response.Status = "failing"
+				response.Checks[checker.Name()] = "failing"
+				return
+			}
+			response.Checks[checker.Name()] = "ok"
+		}()
+	}
+	wg.Wait()
+
+	status := http.StatusOK
+	if response.Status != "ok" {
+		status = http.StatusServiceUnavailable
+	}
+
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(response)
+}
+
+// Version reports the build the binary comes from, as recorded by the Go toolchain.
+func (h *healthHandler) Version(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	response := versionResponse{Version: "unknown"}
+
+	info, ok := debug.ReadBuildInfo()
+	if ok {
+		response.Version = info.Main.Version
+		response.GoVersion = info.GoVersion
+
+		for _, setting := range info.Settings {
+			switch setting.Key {
+			case "vcs.revision":
+				response.Revision = setting.Value
+			case "vcs.time":
+				response.Time = setting.Value
+			case "vcs.modified":
+				response.Modified = setting.Value == "true"
+			}
+		}
+	}
+
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/go-chi/chi/v5"
+	"github.com/petrkoval/social-network-back/internal/domain"
+	"github.com/petrkoval/social-network-back/internal/services"
+	"github.com/petrkoval/social-network-back/internal/storage"
+	http2 "github.com/petrkoval/social-network-back/internal/transport/http"
+	"github.com/petrkoval/social-network-back/internal/transport/http/middlewares"
+	"github.com/petrkoval/social-network-back/internal/transport/http/problem"
+	"github.com/petrkoval/social-network-back/pkg/validation"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+	"io"
+	"net/http"
+	"strconv"
+)
+
+const (
+	mediaPath     = "/media"
+	mediaByIDUrl  = "/{id}"
+	mediaFileForm = "file"
+
+	// multipartOverhead covers the multipart boundaries and headers around the file.
+	multipartOverhead = 64 << 10
+)
+
+type MediaService interface {
+	Upload(ctx context.Context, userID string, file io.Reader) (*domain.Media, error)
+	Open(ctx context.Context, id, variant string) (*domain.MediaVariant, io.ReadCloser, error)
+}
+
+type mediaHandler struct {
+	tokenService tokenService
+	service      MediaService
+	maxSize      int64
+	logger       *zerolog.Logger
+	router       *chi.Mux
+}
+
+func NewMediaHandler(s MediaService, maxSize int64, t tokenService, l *zerolog.Logger) Handler {
+	r := chi.NewRouter()
+
+	return &mediaHandler{
+		tokenService: t,
+		service:      s,
+		maxSize:      maxSize,
+		logger:       l,
+		router:       r,
+	}
+}
+
+// MountOn lifts the server timeouts for the media routes: uploads and downloads of
+// large files on slow connections take longer than any other request.
+func (h *mediaHandler) MountOn(router *http2.Router) {
+	h.router.Use(middlewares.NoTimeout)
+
+	h.router.With(func(next http.Handler) http.Handler {
+		return middlewares.Auth(next, h.tokenService, h.logger)
+	}).Post("/", h.Upload)
+	h.router.With(middlewares.UUIDParam("id", storage.NotFoundMediaErr)).Get(mediaByIDUrl, h.Download)
+
+	router.Mount(mediaPath, h.router)
+}
+
+// Upload expects a multipart form with the file in the "file" field. The body is
+// streamed rather than parsed into memory or temporary files up front.
+func (h *mediaHandler) Upload(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	var (
+		user, _ = middlewares.UserFromContext(r.Context())
+	)
+
+	r.Body = http.MaxBytesReader(w, r.Body, h.maxSize+multipartOverhead)
+
+	file, err := formFile(r, mediaFileForm)
+	if err != nil {
+		WriteErrorResponse(w, r, uploadError(err), h.logger)
+		return
+	}
+
+	entity, err := h.service.Upload(r.Context(), user.ID, file)
+
+	if err != nil {
+		WriteErrorResponse(w, r, uploadError(err), h.logger)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(entity)
+}
+
+// Download streams a variant of the media, picked by the "variant" query parameter
+// and the full size image by default. Variants never change, so they can be cached forever.
+func (h *mediaHandler) Download(w http.ResponseWriter, r *http.Request) {
+	var (
+		id      = chi.URLParam(r, "id")
+		variant = r.URL.Query().Get("variant")
+	)
+
+	media, content, err := h.service.Open(r.Context(), id, variant)
+
+	if err != nil {
+		WriteErrorResponse(w, r, err, h.logger)
+		return
+	}
+	defer content.Close()
+
+	w.Header().Set("Content-Type", media.ContentType)
+	w.Header().Set("Content-Length", strconv.FormatInt(media.Size, 10))
+	w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(http.StatusOK)
+	_, _ = io.Copy(w, content)
+}
+
+// formFile returns the first part of the multipart body sent in the given field.
+func formFile(r *http.Request, field string) (io.Reader, error) {
+	reader, err := r.MultipartReader()
+	if err != nil {
+		return nil, problem.InvalidMultipartErr
+	}
+
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			return nil, validation.Errors{{Field: field, Code: validation.CodeRequired}}
+		}
+
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return nil, err
+		}
+		if err != nil {
+			return nil, problem.InvalidMultipartErr
+		}
+
+		if part.FormName() == field {
+			return part, nil
+		}
+	}
+}
+
+// uploadError reports running into the body limit, wherever the upload was read, as the file being too large.
+func uploadError(err error) error {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return services.MediaTooLargeErr
+	}
+
+	return err
+}
@@ -0,0 +1,192 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/go-chi/chi/v5"
+	"github.com/petrkoval/social-network-back/internal/domain"
+	"github.com/petrkoval/social-network-back/internal/storage"
+	http2 "github.com/petrkoval/social-network-back/internal/transport/http"
+	"github.com/petrkoval/social-network-back/internal/transport/http/middlewares"
+	"github.com/petrkoval/social-network-back/pkg/pagination"
+	"github.com/rs/zerolog"
+	"net/http"
+)
+
+const (
+	conversationsPath       = "/conversations"
+	conversationByIDUrl     = "/{id}"
+	conversationMessagesUrl = "/messages"
+	conversationReadUrl     = "/read"
+)
+
+type MessageService interface {
+	StartConversation(ctx context.Context, userID string, dto domain.CreateConversationDTO) (*domain.Conversation, error)
+	Conversation(ctx context.Context, userID, id string) (*domain.Conversation, error)
+	Conversations(ctx context.Context, userID, cursor, limit string) (*pagination.Page[*domain.Conversation], error)
+	Messages(ctx context.Context, userID, conversationID, cursor, limit string) (*pagination.Page[*domain.Message], error)
+	Send(ctx context.Context, dto domain.SendMessageDTO) (*domain.Message, error)
+	MarkRead(ctx context.Context, userID, conversationID string, dto domain.MarkReadDTO) (*domain.Conversation, error)
+}
+
+type messageHandler struct {
+	tokenService tokenService
+	service      MessageService
+	logger       *zerolog.Logger
+	router       *chi.Mux
+}
+
+func NewMessageHandler(s MessageService, t tokenService, l *zerolog.Logger) Handler {
+	r := chi.NewRouter()
+
+	return &messageHandler{
+		tokenService: t,
+		service:      s,
+		logger:       l,
+		router:       r,
+	}
+}
+
+func (h *messageHandler) MountOn(router *http2.Router) {
+	h.router.Use(func(next http.Handler) http.Handler {
+		return middlewares.Auth(next, h.tokenService, h.logger)
+	})
+
+	h.router.Get("/", h.Conversations)
+	h.router.Post("/", h.StartConversation)
+
+	h.router.Route(conversationByIDUrl, func(r chi.Router) {
+		r.Use(middlewares.UUIDParam("id", storage.NotFoundConversationErr))
+		r.Get("/", h.Conversation)
+		r.Get(conversationMessagesUrl, h.Messages)
+		r.Post(conversationMessagesUrl, h.Send)
+		r.Put(conversationReadUrl, h.MarkRead)
+	})
+
+	router.Mount(conversationsPath, h.router)
+}
+
+func (h *messageHandler) Conversations(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	var (
+		user, _ = middlewares.UserFromContext(r.Context())
+		query   = r.URL.Query()
+	)
+
+	page, err := h.service.Conversations(r.Context(), user.ID, query.Get("cursor"), query.Get("limit"))
+
+	if err != nil {
+		WriteErrorResponse(w, r, err, h.logger)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(page)
+}
+
+func (h *messageHandler) StartConversation(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	var (
+		user, _ = middlewares.UserFromContext(r.Context())
+		dto     = domain.CreateConversationDTO{}
+	)
+
+	if !decodeJSON(w, r, &dto, h.logger) {
+		return
+	}
+
+	entity, err := h.service.StartConversation(r.Context(), user.ID, dto)
+
+	if err != nil {
+		WriteErrorResponse(w, r, err, h.logger)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(entity)
+}
+
+func (h *messageHandler) Conversation(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	var (
+		user, _ = middlewares.UserFromContext(r.Context())
+		id      = chi.URLParam(r, "id")
+	)
+
+	entity, err := h.service.Conversation(r.Context(), user.ID, id)
+
+	if err != nil {
+		WriteErrorResponse(w, r, err, h.logger)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(entity)
+}
+
+func (h *messageHandler) Messages(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	var (
+		user, _ = middlewares.UserFromContext(r.Context())
+		id      = chi.URLParam(r, "id")
+		query   = r.URL.Query()
+	)
+
+	page, err := h.service.Messages(r.Context(), user.ID, id, query.Get("cursor"), query.Get("limit"))
+
+	if err != nil {
+		WriteErrorResponse(w, r, err, h.logger)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(page)
+}
+
+func (h *messageHandler) Send(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	var (
+		user, _ = middlewares.UserFromContext(r.Context())
+		dto     = domain.SendMessageDTO{}
+	)
+
+	if !decodeJSON(w, r, &dto, h.logger) {
+		return
+	}
+
+	dto.ConversationID = chi.URLParam(r, "id")
+	dto.UserID = user.ID
+
+	entity, err := h.service.Send(r.Context(), dto)
+
+	if err != nil {
+		WriteErrorResponse(w, r, err, h.logger)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(entity)
+}
+
+func (h *messageHandler) MarkRead(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	var (
+		user, _ = middlewares.UserFromContext(r.Context())
+		id      = chi.URLParam(r, "id")
+		dto     = domain.MarkReadDTO{}
+	)
+
+	if !decodeJSON(w, r, &dto, h.logger) {
+		return
+	}
+
+	entity, err := h.service.MarkRead(r.Context(), user.ID, id, dto)
+
+	if err != nil {
+		WriteErrorResponse(w, r, err, h.logger)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(entity)
+}
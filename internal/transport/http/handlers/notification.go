@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/go-chi/chi/v5"
+	"github.com/petrkoval/social-network-back/internal/domain"
+	http2 "github.com/petrkoval/social-network-back/internal/transport/http"
+	"github.com/petrkoval/social-network-back/internal/transport/http/middlewares"
+	"github.com/petrkoval/social-network-back/pkg/pagination"
+	"github.com/petrkoval/social-network-back/pkg/validation"
+	"github.com/rs/zerolog"
+	"net/http"
+)
+
+const (
+	notificationsPath           = "/notifications"
+	notificationsReadUrl        = "/read"
+	notificationsUnreadCountUrl = "/unread-count"
+)
+
+type NotificationService interface {
+	Notifications(ctx context.Context, userID, unread, cursor, limit string) (*pagination.Page[*domain.Notification], error)
+	MarkRead(ctx context.Context, userID string, dto domain.MarkNotificationsReadDTO) error
+	CountUnread(ctx context.Context, userID string) (int, error)
+}
+
+type unreadCountResponse struct {
+	Count int `json:"count"`
+}
+
+type notificationHandler struct {
+	tokenService tokenService
+	service      NotificationService
+	logger       *zerolog.Logger
+	router       *chi.Mux
+}
+
+func NewNotificationHandler(s NotificationService, t tokenService, l *zerolog.Logger) Handler {
+	r := chi.NewRouter()
+
+	return &notificationHandler{
+		tokenService: t,
+		service:      s,
+		logger:       l,
+		router:       r,
+	}
+}
+
+func (h *notificationHandler) MountOn(router *http2.Router) {
+	h.router.Use(func(next http.Handler) http.Handler {
+		return middlewares.Auth(next, h.tokenService, h.logger)
+	})
+
+	h.router.Get("/", h.Notifications)
+	h.router.Post(notificationsReadUrl, h.MarkRead)
+	h.router.Get(notificationsUnreadCountUrl, h.CountUnread)
+
+	router.Mount(notificationsPath, h.router)
+}
+
+func (h *notificationHandler) Notifications(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	var (
+		user, _ = middlewares.UserFromContext(r.Context())
+		query   = r.URL.Query()
+	)
+
+	page, err := h.service.Notifications(r.Context(), user.ID, query.Get("unread"), query.Get("cursor"), query.Get("limit"))
+
+	if err != nil {
+		WriteErrorResponse(w, r, err, h.logger)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(page)
+}
+
+func (h *notificationHandler) MarkRead(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	var (
+		user, _ = middlewares.UserFromContext(r.Context())
+		dto     = domain.MarkNotificationsReadDTO{}
+	)
+
+	if !decodeJSON(w, r, &dto, h.logger) {
+		return
+	}
+
+	// Marking everything read takes an explicit "all", so that a client sending
+	// an empty list by mistake does not wipe the unread state.
+	switch {
+	case !dto.All && len(dto.Keys) == 0:
+		WriteErrorResponse(w, r, validation.Errors{{Field: "keys", Code: validation.CodeRequired}}, h.logger)
+		return
+	case dto.All && len(dto.Keys) > 0:
+		WriteErrorResponse(w, r, validation.Errors{{Field: "keys", Code: validation.CodeNotAllowed}}, h.logger)
+		return
+	}
+
+	err := h.service.MarkRead(r.Context(), user.ID, dto)
+
+	if err != nil {
+		WriteErrorResponse(w, r, err, h.logger)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *notificationHandler) CountUnread(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	var (
+		user, _ = middlewares.UserFromContext(r.Context())
+	)
+
+	count, err := h.service.CountUnread(r.Context(), user.ID)
+
+	if err != nil {
+		WriteErrorResponse(w, r, err, h.logger)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(unreadCountResponse{Count: count})
+}
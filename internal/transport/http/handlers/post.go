@@ -0,0 +1,300 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/go-chi/chi/v5"
+	"github.com/petrkoval/social-network-back/internal/domain"
+	"github.com/petrkoval/social-network-back/internal/storage"
+	http2 "github.com/petrkoval/social-network-back/internal/transport/http"
+	"github.com/petrkoval/social-network-back/internal/transport/http/middlewares"
+	"github.com/petrkoval/social-network-back/pkg/pagination"
+	"github.com/rs/zerolog"
+	"net/http"
+)
+
+const (
+	postsPath         = "/posts"
+	postsByUserUrl    = "/user"
+	postsByChannelUrl = "/channel"
+	postByIDUrl       = "/{id}"
+	postLikeUrl       = "/like"
+	postCommentsUrl   = "/comments"
+)
+
+type PostService interface {
+	FindByID(ctx context.Context, viewerID, id string) (*domain.Post, error)
+	FindByUserID(ctx context.Context, viewerID, userID, cursor, limit string) (*pagination.Page[*domain.Post], error)
+	FindByChannelID(ctx context.Context, viewerID, channelID, cursor, limit string) (*pagination.Page[*domain.Post], error)
+	Create(ctx context.Context, dto domain.CreatePostDTO) (*domain.Post, error)
+	Update(ctx context.Context, userID, id string, dto domain.UpdatePostDTO) (*domain.Post, error)
+	Delete(ctx context.Context, userID, id string) error
+}
+
+type LikeService interface {
+	Like(ctx context.Context, userID, postID string) (*domain.Post, error)
+	Unlike(ctx context.Context, userID, postID string) (*domain.Post, error)
+}
+
+type postHandler struct {
+	tokenService   tokenService
+	service        PostService
+	likeService    LikeService
+	commentService CommentService
+	logger         *zerolog.Logger
+	router         *chi.Mux
+}
+
+func NewPostHandler(s PostService, ls LikeService, cs CommentService, t tokenService, l *zerolog.Logger) Handler {
+	r := chi.NewRouter()
+
+	return &postHandler{
+		tokenService:   t,
+		service:        s,
+		likeService:    ls,
+		commentService: cs,
+		logger:         l,
+		router:         r,
+	}
+}
+
+func (h *postHandler) MountOn(router *http2.Router) {
+	authMiddleware := func(next http.Handler) http.Handler {
+		return middlewares.Auth(next, h.tokenService, h.logger)
+	}
+	optionalAuthMiddleware := func(next http.Handler) http.Handler {
+		return middlewares.OptionalAuth(next, h.tokenService, h.logger)
+	}
+
+	h.router.With(optionalAuthMiddleware).Get(postsByUserUrl, h.FindByUserID)
+	h.router.With(optionalAuthMiddleware).Get(postsByChannelUrl, h.FindByChannelID)
+	h.router.With(authMiddleware).Post("/", h.Create)
+
+	h.router.Route(postByIDUrl, func(r chi.Router) {
+		r.Use(middlewares.UUIDParam("id", storage.NotFoundPostErr))
+		r.With(optionalAuthMiddleware).Get("/", h.FindByID)
+		r.With(authMiddleware).Patch("/", h.Update)
+		r.With(authMiddleware).Delete("/", h.Delete)
+		r.With(authMiddleware).Put(postLikeUrl, h.Like)
+		r.With(authMiddleware).Delete(postLikeUrl, h.Unlike)
+		r.Get(postCommentsUrl, h.FindComments)
+		r.With(authMiddleware).Post(postCommentsUrl, h.CreateComment)
+	})
+
+	router.Mount(postsPath, h.router)
+}
+
+func (h *postHandler) FindByID(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	var (
+		id = chi.URLParam(r, "id")
+	)
+
+	entity, err := h.service.FindByID(r.Context(), viewerID(r), id)
+
+	if err != nil {
+		WriteErrorResponse(w, r, err, h.logger)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(entity)
+}
+
+func (h *postHandler) FindByUserID(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	var (
+		query = r.URL.Query()
+	)
+
+	userID, err := queryID(query, "user_id")
+	if err != nil {
+		WriteErrorResponse(w, r, err, h.logger)
+		return
+	}
+
+	page, err := h.service.FindByUserID(r.Context(), viewerID(r), userID, query.Get("cursor"), query.Get("limit"))
+
+	if err != nil {
+		WriteErrorResponse(w, r, err, h.logger)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(page)
+}
+
+func (h *postHandler) FindByChannelID(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	var (
+		query = r.URL.Query()
+	)
+
+	channelID, err := queryID(query, "channel_id")
+	if err != nil {
+		WriteErrorResponse(w, r, err, h.logger)
+		return
+	}
+
+	page, err := h.service.FindByChannelID(r.Context(), viewerID(r), channelID, query.Get("cursor"), query.Get("limit"))
+
+	if err != nil {
+		WriteErrorResponse(w, r, err, h.logger)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(page)
+}
+
+func (h *postHandler) Create(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	var (
+		user, _ = middlewares.UserFromContext(r.Context())
+		dto     = domain.CreatePostDTO{}
+	)
+
+	if !decodeJSON(w, r, &dto, h.logger) {
+		return
+	}
+
+	dto.UserID = user.ID
+
+	entity, err := h.service.Create(r.Context(), dto)
+
+	if err != nil {
+		WriteErrorResponse(w, r, err, h.logger)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(entity)
+}
+
+func (h *postHandler) Update(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	var (
+		user, _ = middlewares.UserFromContext(r.Context())
+		id      = chi.URLParam(r, "id")
+		dto     = domain.UpdatePostDTO{}
+	)
+
+	if !decodeJSON(w, r, &dto, h.logger) {
+		return
+	}
+
+	entity, err := h.service.Update(r.Context(), user.ID, id, dto)
+
+	if err != nil {
+		WriteErrorResponse(w, r, err, h.logger)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(entity)
+}
+
+func (h *postHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	var (
+		user, _ = middlewares.UserFromContext(r.Context())
+		id      = chi.URLParam(r, "id")
+	)
+
+	err := h.service.Delete(r.Context(), user.ID, id)
+
+	if err != nil {
+		WriteErrorResponse(w, r, err, h.logger)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *postHandler) Like(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	var (
+		user, _ = middlewares.UserFromContext(r.Context())
+		id      = chi.URLParam(r, "id")
+	)
+
+	entity, err := h.likeService.Like(r.Context(), user.ID, id)
+
+	if err != nil {
+		WriteErrorResponse(w, r, err, h.logger)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(entity)
+}
+
+func (h *postHandler) Unlike(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	var (
+		user, _ = middlewares.UserFromContext(r.Context())
+		id      = chi.URLParam(r, "id")
+	)
+
+	entity, err := h.likeService.Unlike(r.Context(), user.ID, id)
+
+	if err != nil {
+		WriteErrorResponse(w, r, err, h.logger)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(entity)
+}
+
+func (h *postHandler) FindComments(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	var (
+		id    = chi.URLParam(r, "id")
+		query = r.URL.Query()
+	)
+
+	page, err := h.commentService.FindByPostID(r.Context(), id, query.Get("cursor"), query.Get("limit"))
+
+	if err != nil {
+		WriteErrorResponse(w, r, err, h.logger)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(page)
+}
+
+func (h *postHandler) CreateComment(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	var (
+		user, _ = middlewares.UserFromContext(r.Context())
+		dto     = domain.CreateCommentDTO{}
+	)
+
+	if !decodeJSON(w, r, &dto, h.logger) {
+		return
+	}
+
+	dto.PostID = chi.URLParam(r, "id")
+	dto.UserID = user.ID
+
+	entity, err := h.commentService.Create(r.Context(), dto)
+
+	if err != nil {
+		WriteErrorResponse(w, r, err, h.logger)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(entity)
+}
+
+// viewerID returns the id of the authenticated caller or an empty string for anonymous requests.
+func viewerID(r *http.Request) string {
+	if user, ok := middlewares.UserFromContext(r.Context()); ok {
+		return user.ID
+	}
+
+	return ""
+}
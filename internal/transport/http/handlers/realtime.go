@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"github.com/go-chi/chi/v5"
+	"github.com/gorilla/websocket"
+	http2 "github.com/petrkoval/social-network-back/internal/transport/http"
+	"github.com/petrkoval/social-network-back/internal/transport/http/middlewares"
+	"github.com/rs/zerolog"
+	"net/http"
+)
+
+const (
+	realtimePath = "/ws"
+)
+
+type RealtimeHub interface {
+	Serve(w http.ResponseWriter, r *http.Request, upgrader *websocket.Upgrader, userID string) error
+}
+
+type realtimeHandler struct {
+	tokenService tokenService
+	hub          RealtimeHub
+	upgrader     *websocket.Upgrader
+	logger       *zerolog.Logger
+	router       *chi.Mux
+}
+
+// NewRealtimeHandler serves the WebSocket endpoint. Handshakes are only accepted
+// from the origins allowed for regular requests.
+func NewRealtimeHandler(hub RealtimeHub, t tokenService, l *zerolog.Logger) Handler {
+	r := chi.NewRouter()
+
+	return &realtimeHandler{
+		tokenService: t,
+		hub:          hub,
+		upgrader: &websocket.Upgrader{
+			ReadBufferSize:  1024,
+			WriteBufferSize: 1024,
+			CheckOrigin:     middlewares.AllowedOrigin,
+		},
+		logger: l,
+		router: r,
+	}
+}
+
+func (h *realtimeHandler) MountOn(router *http2.Router) {
+	h.router.Use(func(next http.Handler) http.Handler {
+		return middlewares.WebSocketAuth(next, h.tokenService, h.logger)
+	})
+
+	h.router.Get("/", h.Connect)
+
+	router.Mount(realtimePath, h.router)
+}
+
+func (h *realtimeHandler) Connect(w http.ResponseWriter, r *http.Request) {
+	var (
+		user, _ = middlewares.UserFromContext(r.Context())
+	)
+
+	// On failure the upgrader has already replied to the client.
+	err := h.hub.Serve(w, r, h.upgrader, user.ID)
+	if err != nil {
+		h.logger.Debug().Err(err).Msg("websocket upgrade failed")
+	}
+}
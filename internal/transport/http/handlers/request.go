@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"encoding/json"
+	"github.com/google/uuid"
+	"github.com/petrkoval/social-network-back/internal/transport/http/problem"
+	"github.com/petrkoval/social-network-back/pkg/validation"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// maxBodySize limits JSON request bodies; uploads have their own limit.
+const maxBodySize = 1 << 20
+
+const unknownFieldText = "json: unknown field "
+
+// decodeJSON reads the request body into dst and validates it. An empty body counts
+// as an empty object. Unknown fields and values of the wrong type are reported like
+// failed validation rules. When it returns false, the error response has already been written.
+func decodeJSON(w http.ResponseWriter, r *http.Request, dst any, l *zerolog.Logger) bool {
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodySize))
+	decoder.DisallowUnknownFields()
+
+	err := decoder.Decode(dst)
+	if err == io.EOF {
+		err = nil
+	} else if err == nil && decoder.Decode(&struct{}{}) != io.EOF {
+		err = problem.InvalidJSONErr
+	}
+	if err == nil {
+		err = validation.Validate(dst)
+	}
+
+	if err != nil {
+		var (
+			maxBytesErr *http.MaxBytesError
+			typeErr     *json.UnmarshalTypeError
+			fieldErrs   validation.Errors
+		)
+
+		switch {
+		case errors.As(err, &maxBytesErr):
+			problem.Write(w, r, problem.BodyTooLargeErr)
+		case errors.As(err, &fieldErrs):
+			problem.Write(w, r, fieldErrs)
+		case errors.As(err, &typeErr):
+			problem.Write(w, r, validation.Errors{
+				{Field: typeErr.Field, Code: validation.CodeInvalidType},
+			})
+		case errors.Is(err, validation.InvalidTagErr):
+			WriteErrorResponse(w, r, err, l)
+		case strings.HasPrefix(err.Error(), unknownFieldText):
+			// encoding/json has no error type for unknown fields, only this message.
+			problem.Write(w, r, validation.Errors{
+				{Field: strings.Trim(strings.TrimPrefix(err.Error(), unknownFieldText), `"`), Code: validation.CodeUnknownField},
+			})
+		default:
+			problem.Write(w, r, problem.InvalidJSONErr)
+		}
+
+		return false
+	}
+
+	return true
+}
+
+// queryID returns the uuid in the named query parameter, or the validation error
+// reporting it as missing or malformed.
+func queryID(query url.Values, name string) (string, error) {
+	id := query.Get(name)
+	if id == "" {
+		return "", validation.Errors{{Field: name, Code: validation.CodeRequired}}
+	}
+
+	_, err := uuid.Parse(id)
+	if err != nil {
+		return "", validation.Errors{{Field: name, Code: validation.CodeInvalidUUID}}
+	}
+
+	return id, nil
+}
@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/petrkoval/social-network-back/internal/transport/http/problem"
+	"github.com/petrkoval/social-network-back/pkg/validation"
+	"github.com/rs/zerolog"
+)
+
+type testDTO struct {
+	Title string   `json:"title" validate:"required,max=8"`
+	Tags  []string `json:"tags"  validate:"max=2"`
+	Count int      `json:"count"`
+}
+
+func TestDecodeJSON(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   string
+		ok     bool
+		status int
+		code   string
+		errors validation.Errors
+	}{
+		{"valid", `{"title":"hello","tags":["a"],"count":2}`, true, 0, "", nil},
+		{"empty body", ``, false, http.StatusUnprocessableEntity, "validation_failed",
+			validation.Errors{{Field: "title", Code: validation.CodeRequired}}},
+		{"failed rules", `{"title":"far too long","tags":["a","b","c"]}`, false, http.StatusUnprocessableEntity, "validation_failed",
+			validation.Errors{{Field: "title", Code: validation.CodeTooLong, Limit: 8}, {Field: "tags", Code: validation.CodeTooLong, Limit: 2}}},
+		{"unknown field", `{"title":"hello","admin":true}`, false, http.StatusUnprocessableEntity, "validation_failed",
+			validation.Errors{{Field: "admin", Code: validation.CodeUnknownField}}},
+		{"wrong type", `{"title":"hello","count":"two"}`, false, http.StatusUnprocessableEntity, "validation_failed",
+			validation.Errors{{Field: "count", Code: validation.CodeInvalidType}}},
+		{"trailing data", `{"title":"hello"} {"title":"again"}`, false, http.StatusBadRequest, "invalid_json", nil},
+		{"trailing garbage", `{"title":"hello"}]`, false, http.StatusBadRequest, "invalid_json", nil},
+		{"syntax error", `{"title":`, false, http.StatusBadRequest, "invalid_json", nil},
+		{"too large", `{"title":"` + strings.Repeat("a", maxBodySize) + `"}`, false, http.StatusRequestEntityTooLarge, "body_too_large", nil},
+	}
+
+	logger := zerolog.Nop()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				w   = httptest.NewRecorder()
+				r   = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+				dto testDTO
+			)
+
+			ok := decodeJSON(w, r, &dto, &logger)
+			if ok != tt.ok {
+				t.Fatalf("got %t, want %t", ok, tt.ok)
+			}
+			if ok {
+				return
+			}
+
+			var p problem.Problem
+			err := json.NewDecoder(w.Body).Decode(&p)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if w.Code != tt.status || p.Status != tt.status || p.Code != tt.code {
+				t.Fatalf("got %d %s, want %d %s", w.Code, p.Code, tt.status, tt.code)
+			}
+			if !reflect.DeepEqual(p.Errors, tt.errors) {
+				t.Fatalf("got errors %v, want %v", p.Errors, tt.errors)
+			}
+		})
+	}
+}
+
+func TestDecodeJSONInvalidTag(t *testing.T) {
+	var (
+		w      = httptest.NewRecorder()
+		r      = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"title":"hello"}`))
+		logger = zerolog.Nop()
+		dto    struct {
+			Title string `json:"title" validate:"requird"`
+		}
+	)
+
+	if decodeJSON(w, r, &dto, &logger) {
+		t.Fatal("a struct with an invalid tag was accepted")
+	}
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("got %d, want 500", w.Code)
+	}
+}
+
+func TestQueryID(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		id    string
+		code  string
+	}{
+		{"uuid", "user_id=0b7f6a1e-3c2d-4e5f-8a9b-1c2d3e4f5a6b", "0b7f6a1e-3c2d-4e5f-8a9b-1c2d3e4f5a6b", ""},
+		{"missing", "", "", validation.CodeRequired},
+		{"not a uuid", "user_id=42", "", validation.CodeInvalidUUID},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, _ := url.ParseQuery(tt.query)
+
+			id, err := queryID(query, "user_id")
+			if id != tt.id {
+				t.Fatalf("got id %q, want %q", id, tt.id)
+			}
+			if tt.code == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+
+			want := validation.Errors{{Field: "user_id", Code: tt.code}}
+			if !reflect.DeepEqual(err, want) {
+				t.Fatalf("got %v, want %v", err, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/go-chi/chi/v5"
+	"github.com/petrkoval/social-network-back/internal/domain"
+	http2 "github.com/petrkoval/social-network-back/internal/transport/http"
+	"github.com/petrkoval/social-network-back/internal/transport/http/middlewares"
+	"github.com/rs/zerolog"
+	"net/http"
+)
+
+const (
+	searchPath = "/search"
+)
+
+type SearchService interface {
+	Search(ctx context.Context, viewerID, text, kind, limit string) (*domain.SearchResults, error)
+}
+
+type searchHandler struct {
+	tokenService tokenService
+	service      SearchService
+	logger       *zerolog.Logger
+	router       *chi.Mux
+}
+
+func NewSearchHandler(s SearchService, t tokenService, l *zerolog.Logger) Handler {
+	r := chi.NewRouter()
+
+	return &searchHandler{
+		tokenService: t,
+		service:      s,
+		logger:       l,
+		router:       r,
+	}
+}
+
+func (h *searchHandler) MountOn(router *http2.Router) {
+	h.router.Use(func(next http.Handler) http.Handler {
+		return middlewares.OptionalAuth(next, h.tokenService, h.logger)
+	})
+
+	h.router.Get("/", h.Search)
+
+	router.Mount(searchPath, h.router)
+}
+
+// Search expects the text in "q" and optionally one of "users", "channels" or "posts" in "type".
+func (h *searchHandler) Search(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	var (
+		query = r.URL.Query()
+	)
+
+	results, err := h.service.Search(r.Context(), viewerID(r), query.Get("q"), query.Get("type"), query.Get("limit"))
+
+	if err != nil {
+		WriteErrorResponse(w, r, err, h.logger)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(results)
+}
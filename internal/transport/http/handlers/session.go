@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/go-chi/chi/v5"
+	"github.com/petrkoval/social-network-back/internal/domain"
+	"github.com/petrkoval/social-network-back/internal/storage"
+	http2 "github.com/petrkoval/social-network-back/internal/transport/http"
+	"github.com/petrkoval/social-network-back/internal/transport/http/middlewares"
+	"github.com/rs/zerolog"
+	"net/http"
+)
+
+const (
+	sessionsPath   = "/sessions"
+	sessionByIDUrl = "/{id}"
+)
+
+type SessionService interface {
+	FindByUser(ctx context.Context, user *domain.AuthUser) ([]*domain.Session, error)
+	Revoke(ctx context.Context, userID, id string) error
+	RevokeAll(ctx context.Context, userID string) error
+}
+
+type sessionHandler struct {
+	tokenService tokenService
+	service      SessionService
+	logger       *zerolog.Logger
+	router       *chi.Mux
+}
+
+func NewSessionHandler(s SessionService, t tokenService, l *zerolog.Logger) Handler {
+	r := chi.NewRouter()
+
+	return &sessionHandler{
+		tokenService: t,
+		service:      s,
+		logger:       l,
+		router:       r,
+	}
+}
+
+func (h *sessionHandler) MountOn(router *http2.Router) {
+	h.router.Use(func(next http.Handler) http.Handler {
+		return middlewares.Auth(next, h.tokenService, h.logger)
+	})
+
+	h.router.Get("/", h.FindAll)
+	h.router.Delete("/", h.RevokeAll)
+	h.router.With(middlewares.UUIDParam("id", storage.NotFoundSessionErr)).Delete(sessionByIDUrl, h.Revoke)
+
+	router.Mount(sessionsPath, h.router)
+}
+
+func (h *sessionHandler) FindAll(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	var (
+		user, _ = middlewares.UserFromContext(r.Context())
+	)
+
+	entities, err := h.service.FindByUser(r.Context(), user)
+
+	if err != nil {
+		WriteErrorResponse(w, r, err, h.logger)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(entities)
+}
+
+func (h *sessionHandler) Revoke(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	var (
+		user, _ = middlewares.UserFromContext(r.Context())
+		id      = chi.URLParam(r, "id")
+	)
+
+	err := h.service.Revoke(r.Context(), user.ID, id)
+
+	if err != nil {
+		WriteErrorResponse(w, r, err, h.logger)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *sessionHandler) RevokeAll(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	var (
+		user, _ = middlewares.UserFromContext(r.Context())
+	)
+
+	err := h.service.RevokeAll(r.Context(), user.ID)
+
+	if err != nil {
+		WriteErrorResponse(w, r, err, h.logger)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,249 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/go-chi/chi/v5"
+	"github.com/petrkoval/social-network-back/internal/domain"
+	"github.com/petrkoval/social-network-back/internal/storage"
+	http2 "github.com/petrkoval/social-network-back/internal/transport/http"
+	"github.com/petrkoval/social-network-back/internal/transport/http/middlewares"
+	"github.com/petrkoval/social-network-back/pkg/pagination"
+	"github.com/rs/zerolog"
+	"net/http"
+)
+
+const (
+	usersPath         = "/users"
+	userByIDUrl       = "/{id}"
+	userByUsernameUrl = "/by-username/{username}"
+	userFollowUrl     = "/follow"
+	userFollowersUrl  = "/followers"
+	userFollowingUrl  = "/following"
+
+	mePath        = "/me"
+	mePasswordUrl = "/password"
+)
+
+type UserService interface {
+	FindByID(ctx context.Context, id string) (*domain.User, error)
+	FindByUsername(ctx context.Context, username string) (*domain.User, error)
+	Update(ctx context.Context, userID string, dto domain.UpdateUserDTO) (*domain.User, error)
+	ChangePassword(ctx context.Context, user *domain.AuthUser, dto domain.ChangePasswordDTO) error
+}
+
+type FollowService interface {
+	Follow(ctx context.Context, followerID, followeeID string) error
+	Unfollow(ctx context.Context, followerID, followeeID string) error
+	Followers(ctx context.Context, userID, cursor, limit string) (*pagination.Page[*domain.Follower], error)
+	Following(ctx context.Context, userID, cursor, limit string) (*pagination.Page[*domain.Follower], error)
+}
+
+type userHandler struct {
+	tokenService  tokenService
+	service       UserService
+	followService FollowService
+	logger        *zerolog.Logger
+	router        *chi.Mux
+	meRouter      *chi.Mux
+}
+
+func NewUserHandler(s UserService, fs FollowService, t tokenService, l *zerolog.Logger) Handler {
+	return &userHandler{
+		tokenService:  t,
+		service:       s,
+		followService: fs,
+		logger:        l,
+		router:        chi.NewRouter(),
+		meRouter:      chi.NewRouter(),
+	}
+}
+
+func (h *userHandler) MountOn(router *http2.Router) {
+	authMiddleware := func(next http.Handler) http.Handler {
+		return middlewares.Auth(next, h.tokenService, h.logger)
+	}
+
+	h.router.Get(userByUsernameUrl, h.FindByUsername)
+
+	h.router.Route(userByIDUrl, func(r chi.Router) {
+		r.Use(middlewares.UUIDParam("id", storage.NotFoundUserErr))
+		r.Get("/", h.FindByID)
+		r.With(authMiddleware).Put(userFollowUrl, h.Follow)
+		r.With(authMiddleware).Delete(userFollowUrl, h.Unfollow)
+		r.Get(userFollowersUrl, h.Followers)
+		r.Get(userFollowingUrl, h.Following)
+	})
+
+	h.meRouter.Use(authMiddleware)
+	h.meRouter.Get("/", h.Me)
+	h.meRouter.Patch("/", h.UpdateMe)
+	h.meRouter.Post(mePasswordUrl, h.ChangePassword)
+
+	router.Mount(usersPath, h.router)
+	router.Mount(mePath, h.meRouter)
+}
+
+func (h *userHandler) FindByID(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	var (
+		id = chi.URLParam(r, "id")
+	)
+
+	entity, err := h.service.FindByID(r.Context(), id)
+
+	if err != nil {
+		WriteErrorResponse(w, r, err, h.logger)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(entity)
+}
+
+func (h *userHandler) FindByUsername(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	var (
+		username = chi.URLParam(r, "username")
+	)
+
+	entity, err := h.service.FindByUsername(r.Context(), username)
+
+	if err != nil {
+		WriteErrorResponse(w, r, err, h.logger)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(entity)
+}
+
+func (h *userHandler) Me(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	var (
+		user, _ = middlewares.UserFromContext(r.Context())
+	)
+
+	entity, err := h.service.FindByID(r.Context(), user.ID)
+
+	if err != nil {
+		WriteErrorResponse(w, r, err, h.logger)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(entity)
+}
+
+func (h *userHandler) UpdateMe(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	var (
+		user, _ = middlewares.UserFromContext(r.Context())
+		dto     = domain.UpdateUserDTO{}
+	)
+
+	if !decodeJSON(w, r, &dto, h.logger) {
+		return
+	}
+
+	entity, err := h.service.Update(r.Context(), user.ID, dto)
+
+	if err != nil {
+		WriteErrorResponse(w, r, err, h.logger)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(entity)
+}
+
+func (h *userHandler) ChangePassword(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	var (
+		user, _ = middlewares.UserFromContext(r.Context())
+		dto     = domain.ChangePasswordDTO{}
+	)
+
+	if !decodeJSON(w, r, &dto, h.logger) {
+		return
+	}
+
+	err := h.service.ChangePassword(r.Context(), user, dto)
+
+	if err != nil {
+		WriteErrorResponse(w, r, err, h.logger)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *userHandler) Follow(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	var (
+		user, _ = middlewares.UserFromContext(r.Context())
+		id      = chi.URLParam(r, "id")
+	)
+
+	err := h.followService.Follow(r.Context(), user.ID, id)
+
+	if err != nil {
+		WriteErrorResponse(w, r, err, h.logger)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *userHandler) Unfollow(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	var (
+		user, _ = middlewares.UserFromContext(r.Context())
+		id      = chi.URLParam(r, "id")
+	)
+
+	err := h.followService.Unfollow(r.Context(), user.ID, id)
+
+	if err != nil {
+		WriteErrorResponse(w, r, err, h.logger)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *userHandler) Followers(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	var (
+		id    = chi.URLParam(r, "id")
+		query = r.URL.Query()
+	)
+
+	page, err := h.followService.Followers(r.Context(), id, query.Get("cursor"), query.Get("limit"))
+
+	if err != nil {
+		WriteErrorResponse(w, r, err, h.logger)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(page)
+}
+
+func (h *userHandler) Following(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	var (
+		id    = chi.URLParam(r, "id")
+		query = r.URL.Query()
+	)
+
+	page, err := h.followService.Following(r.Context(), id, query.Get("cursor"), query.Get("limit"))
+
+	if err != nil {
+		WriteErrorResponse(w, r, err, h.logger)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(page)
+}
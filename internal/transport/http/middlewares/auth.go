@@ -2,51 +2,111 @@ package middlewares
 
 import (
 	"context"
-	"encoding/json"
+	"github.com/go-chi/chi/v5/middleware"
 	"github.com/petrkoval/social-network-back/internal/domain"
+	"github.com/petrkoval/social-network-back/internal/services"
+	"github.com/petrkoval/social-network-back/internal/transport/http/problem"
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog"
 	"net/http"
 	"strings"
 )
 
-type errorMessage struct {
-	StatusCode int    `json:"status_code,omitempty"`
-	Message    string `json:"message,omitempty"`
-	URL        string `json:"url,omitempty"`
+type service interface {
+	VerifyAccessToken(ctx context.Context, accessToken string) (*domain.AuthUser, error)
 }
 
-func writeErrorResponse(w http.ResponseWriter, r *http.Request, err error, statusCode int) {
-	w.WriteHeader(statusCode)
-	_ = json.NewEncoder(w).Encode(errorMessage{
-		StatusCode: statusCode,
-		Message:    err.Error(),
-		URL:        r.Host + r.URL.Path,
-	})
-}
+// accessTokenParam carries the access token of WebSocket handshakes.
+const accessTokenParam = "access_token"
 
-type service interface {
-	VerifyAccessToken(accessToken string) (*domain.AuthUser, error)
+type contextKey int
+
+const userContextKey contextKey = iota
+
+// UserFromContext returns the user attached by Auth or OptionalAuth.
+func UserFromContext(ctx context.Context) (*domain.AuthUser, bool) {
+	user, ok := ctx.Value(userContextKey).(*domain.AuthUser)
+	return user, ok && user != nil
 }
 
 func Auth(next http.Handler, s service, l *zerolog.Logger) http.Handler {
 
 	l.Debug().Msg("init auth middleware")
+	return authenticate(next, s, l, bearerToken)
+}
+
+// WebSocketAuth is Auth for WebSocket handshakes. Browsers cannot set headers on them,
+// so the access token may also be passed in the access_token query parameter.
+func WebSocketAuth(next http.Handler, s service, l *zerolog.Logger) http.Handler {
+
+	l.Debug().Msg("init websocket auth middleware")
+	return authenticate(next, s, l, func(r *http.Request) (string, bool) {
+		if token, ok := bearerToken(r); ok {
+			return token, true
+		}
+
+		token := r.URL.Query().Get(accessTokenParam)
+		return token, token != ""
+	})
+}
+
+// authenticate rejects requests without a valid access token. A token whose session
+// was revoked is reported as such; failing to look the session up is a server error.
+func authenticate(next http.Handler, s service, l *zerolog.Logger, tokenOf func(r *http.Request) (string, bool)) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		token := r.Header.Get("Authorization")
-		if token == "" {
-			writeErrorResponse(w, r, errors.New("authorization header is empty"), http.StatusUnauthorized)
+		token, ok := tokenOf(r)
+		if !ok {
+			problem.Write(w, r, problem.MissingAccessTokenErr)
 			return
 		}
 
-		user, err := s.VerifyAccessToken(strings.Split(token, " ")[1])
+		user, err := s.VerifyAccessToken(r.Context(), token)
+		switch {
+		case errors.Is(err, services.InvalidTokenErr), errors.Is(err, services.TokenExpiredErr):
+			problem.Write(w, r, problem.InvalidAccessTokenErr)
+			return
+		case err != nil:
+			if problem.Write(w, r, err) >= http.StatusInternalServerError {
+				l.Error().Stack().Err(err).Str("request_id", middleware.GetReqID(r.Context())).Msg("unhandled error")
+			}
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), userContextKey, user)
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// OptionalAuth attaches the user when a valid access token is presented and
+// lets anonymous requests, or requests with an invalid token, through as they are.
+func OptionalAuth(next http.Handler, s service, l *zerolog.Logger) http.Handler {
+
+	l.Debug().Msg("init optional auth middleware")
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := bearerToken(r)
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		user, err := s.VerifyAccessToken(r.Context(), token)
 		if err != nil {
-			writeErrorResponse(w, r, errors.New("authorization header is empty"), http.StatusUnauthorized)
+			next.ServeHTTP(w, r)
 			return
 		}
 
-		ctx := context.WithValue(r.Context(), "user", user)
+		ctx := context.WithValue(r.Context(), userContextKey, user)
 
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
+
+func bearerToken(r *http.Request) (string, bool) {
+	scheme, token, found := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") || token == "" {
+		return "", false
+	}
+
+	return token, true
+}
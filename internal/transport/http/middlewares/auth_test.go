@@ -0,0 +1,84 @@
+package middlewares
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/petrkoval/social-network-back/internal/domain"
+	"github.com/petrkoval/social-network-back/internal/services"
+	"github.com/petrkoval/social-network-back/internal/transport/http/problem"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+)
+
+// fakeTokens verifies a token by looking it up; unknown tokens are invalid.
+type fakeTokens map[string]error
+
+func (f fakeTokens) VerifyAccessToken(_ context.Context, accessToken string) (*domain.AuthUser, error) {
+	err, ok := f[accessToken]
+	if !ok {
+		return nil, errors.Wrap(services.InvalidTokenErr, "fakeTokens.VerifyAccessToken")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.AuthUser{ID: "user", SessionID: "session"}, nil
+}
+
+func TestAuth(t *testing.T) {
+	var (
+		logger = zerolog.Nop()
+		tokens = fakeTokens{
+			"valid":   nil,
+			"revoked": errors.Wrap(services.SessionRevokedErr, "fakeTokens.VerifyAccessToken"),
+			"db down": errors.New("connection refused"),
+		}
+		handler = Auth(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}), tokens, &logger)
+	)
+
+	tests := []struct {
+		name   string
+		header string
+		status int
+		code   string
+	}{
+		{"valid", "Bearer valid", http.StatusOK, ""},
+		{"missing", "", http.StatusUnauthorized, "missing_access_token"},
+		{"invalid", "Bearer forged", http.StatusUnauthorized, "invalid_access_token"},
+		{"revoked session", "Bearer revoked", http.StatusUnauthorized, "session_revoked"},
+		{"failed lookup", "Bearer db down", http.StatusInternalServerError, "internal_error"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.header != "" {
+				r.Header.Set("Authorization", tt.header)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+
+			if w.Code != tt.status {
+				t.Fatalf("got %d, want %d", w.Code, tt.status)
+			}
+			if tt.code == "" {
+				return
+			}
+
+			var p problem.Problem
+			err := json.NewDecoder(w.Body).Decode(&p)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if p.Code != tt.code {
+				t.Fatalf("got code %q, want %q", p.Code, tt.code)
+			}
+		})
+	}
+}
@@ -3,15 +3,25 @@ package middlewares
 import (
 	"github.com/go-chi/cors"
 	"net/http"
+	"slices"
 )
 
+var allowedOrigins = []string{
+	"http://localhost:5173",
+}
+
+// AllowedOrigin reports whether the request comes from an allowed origin or has no Origin at all.
+func AllowedOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	return origin == "" || slices.Contains(allowedOrigins, origin)
+}
+
 var CorsMiddleware = cors.Handler(cors.Options{
-	AllowedOrigins: []string{
-		"http://localhost:5173",
-	},
+	AllowedOrigins: allowedOrigins,
 	AllowedMethods: []string{
 		http.MethodGet,
 		http.MethodPost,
+		http.MethodPut,
 		http.MethodPatch,
 		http.MethodDelete,
 		http.MethodOptions,
@@ -22,6 +32,7 @@ var CorsMiddleware = cors.Handler(cors.Options{
 	},
 	ExposedHeaders: []string{
 		"Content-Type",
+		"X-Request-ID",
 	},
 	AllowCredentials: true,
 })
@@ -1,8 +1,10 @@
 package middlewares
 
 import (
+	"github.com/go-chi/chi/v5/middleware"
 	"github.com/petrkoval/social-network-back/internal/logger"
 	"net/http"
+	"net/url"
 )
 
 func Logger(next http.Handler) http.Handler {
@@ -10,8 +12,28 @@ func Logger(next http.Handler) http.Handler {
 
 	l.Debug().Msg("init logger middleware")
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		l.Info().Msgf("handling %s %s", r.Method, r.RequestURI)
+		id := middleware.GetReqID(r.Context())
+
+		uri := loggedURI(r.URL)
+
+		l.Info().Str("request_id", id).Msgf("handling %s %s", r.Method, uri)
 		next.ServeHTTP(w, r)
-		l.Info().Msgf("responsing %s %s", r.Method, r.RequestURI)
+		l.Info().Str("request_id", id).Msgf("responsing %s %s", r.Method, uri)
 	})
 }
+
+// loggedURI is the request URI with the access token of WebSocket handshakes redacted,
+// so that live tokens never end up in the logs.
+func loggedURI(u *url.URL) string {
+	query := u.Query()
+	if !query.Has(accessTokenParam) {
+		return u.RequestURI()
+	}
+
+	query.Set(accessTokenParam, "REDACTED")
+
+	redacted := *u
+	redacted.RawQuery = query.Encode()
+
+	return redacted.RequestURI()
+}
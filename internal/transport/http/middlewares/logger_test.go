@@ -0,0 +1,30 @@
+package middlewares
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestLoggedURI(t *testing.T) {
+	tests := []struct {
+		uri  string
+		want string
+	}{
+		{"/posts?cursor=abc&limit=10", "/posts?cursor=abc&limit=10"},
+		{"/realtime?access_token=eyJhbGciOi.secret.sig", "/realtime?access_token=REDACTED"},
+		{"/realtime?x=1&access_token=secret", "/realtime?access_token=REDACTED&x=1"},
+	}
+
+	for _, tt := range tests {
+		u, err := url.ParseRequestURI(tt.uri)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got := loggedURI(u)
+		if got != tt.want || strings.Contains(got, "secret") {
+			t.Errorf("loggedURI(%q) = %q, want %q", tt.uri, got, tt.want)
+		}
+	}
+}
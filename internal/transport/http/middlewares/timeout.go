@@ -0,0 +1,51 @@
+package middlewares
+
+import (
+	"context"
+	"github.com/petrkoval/social-network-back/internal/transport/http/problem"
+	"net/http"
+	"time"
+)
+
+type timeoutKey struct{}
+
+// Timeout cancels the context of a request that takes longer than timeout with
+// problem.RequestTimeoutErr as the cause; zero means no timeout. The handler answers
+// as it does to any failure, and the problem package turns its error into a 504.
+// Unlike chi's Timeout, it can be lifted by NoTimeout further down the chain.
+func Timeout(timeout time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if timeout <= 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			ctx, cancel := context.WithCancelCause(r.Context())
+			defer cancel(nil)
+
+			timer := time.AfterFunc(timeout, func() {
+				cancel(problem.RequestTimeoutErr)
+			})
+			defer timer.Stop()
+
+			next.ServeHTTP(w, r.WithContext(context.WithValue(ctx, timeoutKey{}, timer)))
+		})
+	}
+}
+
+// NoTimeout lifts the request timeout and the read and write deadlines of the server
+// for routes that stream large bodies, such as uploads and downloads.
+func NoTimeout(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if timer, ok := r.Context().Value(timeoutKey{}).(*time.Timer); ok {
+			timer.Stop()
+		}
+
+		rc := http.NewResponseController(w)
+		_ = rc.SetReadDeadline(time.Time{})
+		_ = rc.SetWriteDeadline(time.Time{})
+
+		next.ServeHTTP(w, r)
+	})
+}
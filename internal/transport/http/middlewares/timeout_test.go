@@ -0,0 +1,60 @@
+package middlewares
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/petrkoval/social-network-back/internal/transport/http/problem"
+	"github.com/pkg/errors"
+)
+
+// waitForCancel stands for a handler blocked in a query: when the request is cancelled,
+// it answers with the error it got, like every handler does.
+func waitForCancel(w http.ResponseWriter, r *http.Request) {
+	select {
+	case <-r.Context().Done():
+		problem.Write(w, r, errors.Wrap(r.Context().Err(), "storage.Find"))
+	case <-time.After(200 * time.Millisecond):
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	tests := []struct {
+		name    string
+		timeout time.Duration
+		handler http.Handler
+		want    int
+	}{
+		{"timed out", 10 * time.Millisecond, http.HandlerFunc(waitForCancel), http.StatusGatewayTimeout},
+		{"no timeout", 0, http.HandlerFunc(waitForCancel), http.StatusOK},
+		{"lifted", 10 * time.Millisecond, NoTimeout(http.HandlerFunc(waitForCancel)), http.StatusOK},
+		{"in time", time.Second, http.HandlerFunc(waitForCancel), http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			Timeout(tt.timeout)(tt.handler).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+			if w.Code != tt.want {
+				t.Fatalf("got %d, want %d", w.Code, tt.want)
+			}
+			if tt.want != http.StatusGatewayTimeout {
+				return
+			}
+
+			var p problem.Problem
+			err := json.NewDecoder(w.Body).Decode(&p)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if p.Code != "request_timeout" {
+				t.Fatalf("got code %q, want request_timeout", p.Code)
+			}
+		})
+	}
+}
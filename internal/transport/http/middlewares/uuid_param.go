@@ -0,0 +1,25 @@
+package middlewares
+
+import (
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/petrkoval/social-network-back/internal/transport/http/problem"
+	"github.com/pkg/errors"
+	"net/http"
+)
+
+// UUIDParam answers with notFound when the named path parameter is not a uuid: no
+// resource can have such an id, and Postgres would reject it in a uuid column.
+func UUIDParam(name string, notFound error) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, err := uuid.Parse(chi.URLParam(r, name))
+			if err != nil {
+				problem.Write(w, r, errors.Wrap(notFound, "middlewares.UUIDParam"))
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
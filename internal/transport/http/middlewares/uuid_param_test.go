@@ -0,0 +1,52 @@
+package middlewares
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/petrkoval/social-network-back/internal/storage"
+	"github.com/petrkoval/social-network-back/internal/transport/http/problem"
+)
+
+func TestUUIDParam(t *testing.T) {
+	router := chi.NewRouter()
+	router.With(UUIDParam("id", storage.NotFoundPostErr)).Get("/posts/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	tests := []struct {
+		name string
+		path string
+		want int
+	}{
+		{"uuid", "/posts/0b7f6a1e-3c2d-4e5f-8a9b-1c2d3e4f5a6b", http.StatusOK},
+		{"not a uuid", "/posts/42", http.StatusNotFound},
+		{"sql", "/posts/'%20OR%201=1", http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			if w.Code != tt.want {
+				t.Fatalf("got %d, want %d", w.Code, tt.want)
+			}
+			if tt.want != http.StatusNotFound {
+				return
+			}
+
+			var p problem.Problem
+			err := json.NewDecoder(w.Body).Decode(&p)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if p.Code != "post_not_found" {
+				t.Fatalf("got code %q, want post_not_found", p.Code)
+			}
+		})
+	}
+}
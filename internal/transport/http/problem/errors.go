@@ -0,0 +1,18 @@
+package problem
+
+import "github.com/pkg/errors"
+
+// Errors raised by the transport layer itself rather than by services.
+var (
+	InvalidJSONErr      = errors.New("request body is not valid JSON")
+	InvalidMultipartErr = errors.New("request body is not a valid multipart form")
+	BodyTooLargeErr     = errors.New("request body is too large")
+
+	MissingAccessTokenErr  = errors.New("authorization header is empty")
+	InvalidAccessTokenErr  = errors.New("invalid access token")
+	MissingRefreshTokenErr = errors.New("refresh_token cookie is missing")
+
+	// RequestTimeoutErr is the cause the context of a request is cancelled with when
+	// it takes too long.
+	RequestTimeoutErr = errors.New("request timed out")
+)
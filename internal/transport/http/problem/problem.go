@@ -0,0 +1,69 @@
+// Package problem writes error responses as RFC 7807 problem details. The status and
+// code of a response come from the registry, so handlers only pass the error along.
+package problem
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/petrkoval/social-network-back/pkg/validation"
+	"github.com/pkg/errors"
+	"net/http"
+)
+
+const ContentType = "application/problem+json"
+
+// Problem is the body of error responses. Code is stable and meant for clients to
+// branch on; Detail is for humans. Errors lists the invalid fields of a request that failed validation.
+type Problem struct {
+	Type      string            `json:"type"`
+	Title     string            `json:"title"`
+	Status    int               `json:"status"`
+	Detail    string            `json:"detail,omitempty"`
+	Instance  string            `json:"instance,omitempty"`
+	Code      string            `json:"code"`
+	RequestID string            `json:"request_id,omitempty"`
+	Errors    validation.Errors `json:"errors,omitempty"`
+}
+
+// New builds the problem describing err. Only the message of the registered sentinel
+// is exposed, never the wrapped chain; errors that are not registered become a 500
+// with no detail at all. Once the request has timed out, the timeout is reported
+// whatever the handler failed with, usually a cancelled query.
+func New(r *http.Request, err error) *Problem {
+	d := lookup(err)
+	if errors.Is(context.Cause(r.Context()), RequestTimeoutErr) {
+		d = lookup(RequestTimeoutErr)
+	}
+
+	p := &Problem{
+		Type:      "about:blank",
+		Title:     http.StatusText(d.status),
+		Status:    d.status,
+		Instance:  r.URL.Path,
+		Code:      d.code,
+		RequestID: middleware.GetReqID(r.Context()),
+	}
+
+	if d.status < http.StatusInternalServerError {
+		p.Detail = d.target.Error()
+	}
+
+	var fieldErrs validation.Errors
+	if errors.As(err, &fieldErrs) {
+		p.Errors = fieldErrs
+	}
+
+	return p
+}
+
+// Write writes the problem describing err and returns its status.
+func Write(w http.ResponseWriter, r *http.Request, err error) int {
+	p := New(r, err)
+
+	w.Header().Set("Content-Type", ContentType)
+	w.WriteHeader(p.Status)
+	_ = json.NewEncoder(w).Encode(p)
+
+	return p.Status
+}
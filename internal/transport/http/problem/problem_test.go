@@ -0,0 +1,39 @@
+package problem
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/pkg/errors"
+)
+
+func TestTimedOutRequest(t *testing.T) {
+	failed := errors.Wrap(context.Canceled, "storage.Find")
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	if p := New(r, failed); p.Status != http.StatusInternalServerError {
+		t.Fatalf("got %d, want 500 for a request that did not time out", p.Status)
+	}
+
+	ctx, cancel := context.WithCancelCause(context.Background())
+	cancel(RequestTimeoutErr)
+
+	if p := New(r.WithContext(ctx), failed); p.Status != http.StatusGatewayTimeout || p.Code != "request_timeout" {
+		t.Fatalf("got %d %s, want 504 request_timeout", p.Status, p.Code)
+	}
+}
+
+func TestMalformedValue(t *testing.T) {
+	failed := errors.Wrap(&pgconn.PgError{Code: "22P02", Message: `invalid input syntax for type uuid: "42"`}, "PostStorage.FindByID")
+
+	p := New(httptest.NewRequest(http.MethodGet, "/", nil), failed)
+	if p.Status != http.StatusBadRequest || p.Code != "malformed_value" {
+		t.Fatalf("got %d %s, want 400 malformed_value", p.Status, p.Code)
+	}
+	if p.Detail != "malformed value" {
+		t.Fatalf("detail %q exposes the database error", p.Detail)
+	}
+}
@@ -0,0 +1,104 @@
+package problem
+
+import (
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/petrkoval/social-network-back/internal/services"
+	"github.com/petrkoval/social-network-back/internal/storage"
+	"github.com/petrkoval/social-network-back/pkg/blob"
+	"github.com/petrkoval/social-network-back/pkg/pagination"
+	"github.com/petrkoval/social-network-back/pkg/validation"
+	"github.com/pkg/errors"
+	"net/http"
+)
+
+// definition maps a sentinel error to the status and the stable code it is reported with.
+type definition struct {
+	target error
+	status int
+	code   string
+}
+
+var (
+	internal         = definition{target: errors.New("internal server error"), status: http.StatusInternalServerError, code: "internal_error"}
+	validationFailed = definition{target: errors.New("request validation failed"), status: http.StatusUnprocessableEntity, code: "validation_failed"}
+	malformedValue   = definition{target: errors.New("malformed value"), status: http.StatusBadRequest, code: "malformed_value"}
+)
+
+// invalidTextRepresentationCode is what Postgres fails with on a value it cannot cast,
+// such as an id that is not a uuid. Handlers check ids first; this covers the ones they miss.
+const invalidTextRepresentationCode = "22P02"
+
+// registry is matched in order with errors.Is. Codes are part of the API: add new
+// ones freely, but never change or reuse an existing one.
+var registry = []definition{
+	{InvalidJSONErr, http.StatusBadRequest, "invalid_json"},
+	{InvalidMultipartErr, http.StatusBadRequest, "invalid_multipart"},
+	{BodyTooLargeErr, http.StatusRequestEntityTooLarge, "body_too_large"},
+	{MissingAccessTokenErr, http.StatusUnauthorized, "missing_access_token"},
+	{InvalidAccessTokenErr, http.StatusUnauthorized, "invalid_access_token"},
+	{MissingRefreshTokenErr, http.StatusUnauthorized, "missing_refresh_token"},
+	{RequestTimeoutErr, http.StatusGatewayTimeout, "request_timeout"},
+
+	{pagination.InvalidCursorErr, http.StatusBadRequest, "invalid_cursor"},
+	{pagination.InvalidLimitErr, http.StatusBadRequest, "invalid_limit"},
+	{services.QueryParamParsingErr, http.StatusBadRequest, "invalid_query_param"},
+
+	{services.InvalidCredentialsErr, http.StatusUnauthorized, "invalid_credentials"},
+	{services.TokenExpiredErr, http.StatusUnauthorized, "token_expired"},
+	{services.InvalidTokenErr, http.StatusUnauthorized, "invalid_token"},
+	{services.TokenReusedErr, http.StatusUnauthorized, "token_reused"},
+	{services.SessionRevokedErr, http.StatusUnauthorized, "session_revoked"},
+	{storage.NotFoundTokenErr, http.StatusUnauthorized, "token_not_found"},
+	{services.UserExistsErr, http.StatusConflict, "user_exists"},
+	{services.WrongPasswordErr, http.StatusForbidden, "wrong_password"},
+	{services.PasswordTooLongErr, http.StatusBadRequest, "password_too_long"},
+
+	{services.ForbiddenErr, http.StatusForbidden, "forbidden"},
+	{services.EmptyPostErr, http.StatusBadRequest, "empty_post"},
+	{services.EmptyCommentErr, http.StatusBadRequest, "empty_comment"},
+	{services.ForeignParentCommentErr, http.StatusBadRequest, "foreign_parent_comment"},
+	{services.SelfFollowErr, http.StatusBadRequest, "self_follow"},
+	{services.NoConversationMembersErr, http.StatusBadRequest, "no_conversation_members"},
+	{services.TooManyMembersErr, http.StatusBadRequest, "too_many_members"},
+	{services.EmptyMessageErr, http.StatusBadRequest, "empty_message"},
+
+	{services.MediaTooLargeErr, http.StatusRequestEntityTooLarge, "media_too_large"},
+	{services.UnsupportedMediaTypeErr, http.StatusUnsupportedMediaType, "unsupported_media_type"},
+	{services.UnknownMediaErr, http.StatusBadRequest, "unknown_media"},
+	{services.MediaNotReadyErr, http.StatusConflict, "media_not_ready"},
+
+	{services.EmptySearchQueryErr, http.StatusBadRequest, "empty_search_query"},
+	{services.SearchQueryTooLongErr, http.StatusBadRequest, "search_query_too_long"},
+	{services.UnknownSearchTypeErr, http.StatusBadRequest, "unknown_search_type"},
+
+	{storage.NotFoundUserErr, http.StatusNotFound, "user_not_found"},
+	{storage.NotFoundSessionErr, http.StatusNotFound, "session_not_found"},
+	{storage.NotFoundChannelErr, http.StatusNotFound, "channel_not_found"},
+	{storage.NotFoundPostErr, http.StatusNotFound, "post_not_found"},
+	{storage.NotFoundCommentErr, http.StatusNotFound, "comment_not_found"},
+	{storage.NotFoundConversationErr, http.StatusNotFound, "conversation_not_found"},
+	{storage.NotFoundMessageErr, http.StatusNotFound, "message_not_found"},
+	{storage.NotFoundMediaErr, http.StatusNotFound, "media_not_found"},
+	{storage.NotFoundMediaVariantErr, http.StatusNotFound, "media_variant_not_found"},
+	{blob.NotFoundBlobErr, http.StatusNotFound, "media_file_not_found"},
+}
+
+func lookup(err error) definition {
+	var fieldErrs validation.Errors
+	if errors.As(err, &fieldErrs) {
+		return validationFailed
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == invalidTextRepresentationCode {
+		return malformedValue
+	}
+
+	for _, d := range registry {
+		if errors.Is(err, d.target) {
+			return d
+		}
+	}
+
+	return internal
+}
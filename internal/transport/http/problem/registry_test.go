@@ -0,0 +1,146 @@
+package problem
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// sentinelDirs are the packages whose exported *Err sentinels can reach a handler.
+var sentinelDirs = map[string]string{
+	"services":   "../../../services",
+	"storage":    "../../../storage",
+	"pagination": "../../../../pkg/pagination",
+	"blob":       "../../../../pkg/blob",
+}
+
+// internalErrs are sentinels that mean a bug or a broken deployment rather than a bad
+// request, and are meant to be answered with 500.
+var internalErrs = map[string]bool{
+	"services.JwtSigningErr":              true,
+	"services.UnexpectedSigningMethodErr": true,
+	"blob.InvalidKeyErr":                  true,
+}
+
+// translatedErrs are sentinels the services always turn into one of their own, so they
+// never reach a handler.
+var translatedErrs = map[string]bool{
+	"storage.DuplicateUsernameErr": true,
+}
+
+// TestRegistryIsComplete makes sure a new sentinel is not silently answered with 500:
+// it has to be either registered or listed in internalErrs.
+func TestRegistryIsComplete(t *testing.T) {
+	registered := registeredNames(t)
+
+	for pkg, dir := range sentinelDirs {
+		for _, name := range sentinelNames(t, dir) {
+			qualified := pkg + "." + name
+			if !registered[qualified] && !internalErrs[qualified] && !translatedErrs[qualified] {
+				t.Errorf("%s is neither in the registry nor in internalErrs or translatedErrs", qualified)
+			}
+		}
+	}
+
+	for name := range internalErrs {
+		if registered[name] {
+			t.Errorf("%s is both registered and listed as internal", name)
+		}
+	}
+}
+
+func TestRegistryCodesAreUnique(t *testing.T) {
+	codes := map[string]bool{internal.code: true, validationFailed.code: true, malformedValue.code: true}
+
+	for _, d := range registry {
+		if codes[d.code] {
+			t.Errorf("code %q is used twice", d.code)
+		}
+		codes[d.code] = true
+	}
+}
+
+// registeredNames collects the errors of other packages referenced by the registry,
+// e.g. "storage.NotFoundUserErr".
+func registeredNames(t *testing.T) map[string]bool {
+	f, err := parser.ParseFile(token.NewFileSet(), "registry.go", nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	names := make(map[string]bool)
+	for _, decl := range f.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.VAR {
+			continue
+		}
+
+		for _, spec := range gen.Specs {
+			value := spec.(*ast.ValueSpec)
+			if len(value.Names) != 1 || value.Names[0].Name != "registry" {
+				continue
+			}
+
+			ast.Inspect(value, func(n ast.Node) bool {
+				sel, ok := n.(*ast.SelectorExpr)
+				if !ok {
+					return true
+				}
+				if pkg, ok := sel.X.(*ast.Ident); ok {
+					names[pkg.Name+"."+sel.Sel.Name] = true
+				}
+				return true
+			})
+		}
+	}
+
+	if len(names) == 0 {
+		t.Fatal("no registry entries found")
+	}
+
+	return names
+}
+
+// sentinelNames lists the exported package-level variables named *Err in dir.
+func sentinelNames(t *testing.T, dir string) []string {
+	files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+
+		f, err := parser.ParseFile(token.NewFileSet(), file, nil, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, decl := range f.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.VAR {
+				continue
+			}
+
+			for _, spec := range gen.Specs {
+				for _, name := range spec.(*ast.ValueSpec).Names {
+					if name.IsExported() && strings.HasSuffix(name.Name, "Err") {
+						names = append(names, name.Name)
+					}
+				}
+			}
+		}
+	}
+
+	if len(names) == 0 {
+		t.Fatalf("no sentinels found in %s", dir)
+	}
+
+	return names
+}
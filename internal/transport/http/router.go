@@ -1,35 +1,73 @@
 package http
 
 import (
-	"fmt"
+	"context"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/petrkoval/social-network-back/internal/config"
 	middlewares2 "github.com/petrkoval/social-network-back/internal/transport/http/middlewares"
+	"github.com/pkg/errors"
+	"net"
 	"net/http"
+	"strconv"
 	"time"
 )
 
 type Router struct {
-	cfg *config.ServerConfig
+	cfg    *config.ServerConfig
+	server *http.Server
 	*chi.Mux
 }
 
 func NewRouter(cfg *config.ServerConfig) *Router {
-	return &Router{
+	r := &Router{
 		cfg: cfg,
 		Mux: chi.NewRouter(),
 	}
+
+	r.server = &http.Server{
+		Addr:              net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port)),
+		Handler:           r,
+		ReadTimeout:       seconds(cfg.ReadTimeout),
+		ReadHeaderTimeout: seconds(cfg.ReadHeaderTimeout),
+		WriteTimeout:      seconds(cfg.WriteTimeout),
+		IdleTimeout:       seconds(cfg.IdleTimeout),
+	}
+
+	return r
 }
 
+// Start serves until Shutdown is called, after which it returns nil.
 func (r *Router) Start() error {
-	return http.ListenAndServe(fmt.Sprintf(":%d", r.cfg.Port), r)
+	err := r.server.ListenAndServe()
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+
+	return err
+}
+
+// Shutdown stops accepting connections and waits for in-flight requests until ctx is done.
+// Hijacked connections such as WebSockets are neither waited for nor closed.
+func (r *Router) Shutdown(ctx context.Context) error {
+	return r.server.Shutdown(ctx)
+}
+
+// ShutdownTimeout is how long Shutdown should be given to drain in-flight requests.
+func (r *Router) ShutdownTimeout() time.Duration {
+	return seconds(r.cfg.ShutdownTimeout)
 }
 
 func (r *Router) InitMiddlewares() {
+	r.Use(middlewares2.RequestID)
 	r.Use(middlewares2.Logger)
 	r.Use(middlewares2.CorsMiddleware)
 
 	r.Use(middleware.Recoverer)
-	r.Use(middleware.Timeout(time.Duration(r.cfg.WriteTimeout) * time.Second))
+	r.Use(middlewares2.Timeout(seconds(r.cfg.WriteTimeout)))
+}
+
+// seconds converts a timeout from the config; zero means no timeout.
+func seconds(value int) time.Duration {
+	return time.Duration(value) * time.Second
 }
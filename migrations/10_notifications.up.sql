@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS notifications
+(
+    notification_id uuid PRIMARY KEY NOT NULL DEFAULT uuid_generate_v4(),
+    user_id         uuid             NOT NULL REFERENCES users (user_id) ON DELETE CASCADE,
+    actor_id        uuid             NOT NULL REFERENCES users (user_id) ON DELETE CASCADE,
+    type            varchar(32)      NOT NULL,
+    subject_id      uuid             NOT NULL,
+    created_at      timestamp        NOT NULL DEFAULT now(),
+    read_at         timestamp                 DEFAULT NULL,
+    UNIQUE (user_id, actor_id, type, subject_id)
+);
+
+CREATE INDEX IF NOT EXISTS notifications_user_idx ON notifications (user_id, type, subject_id);
+CREATE INDEX IF NOT EXISTS notifications_unread_idx ON notifications (user_id) WHERE read_at IS NULL;
@@ -0,0 +1,6 @@
+ALTER TABLE users DROP COLUMN IF EXISTS avatar_id;
+
+ALTER TABLE posts RENAME COLUMN legacy_images TO images;
+ALTER TABLE posts DROP COLUMN IF EXISTS media_ids;
+
+DROP TABLE IF EXISTS media;
@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS media
+(
+    media_id     uuid PRIMARY KEY NOT NULL DEFAULT uuid_generate_v4(),
+    user_id      uuid             NOT NULL REFERENCES users (user_id) ON DELETE CASCADE,
+    content_hash char(64)         NOT NULL,
+    content_type varchar(64)      NOT NULL,
+    size         bigint           NOT NULL,
+    created_at   timestamp        NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS media_user_idx ON media (user_id);
+CREATE INDEX IF NOT EXISTS media_content_hash_idx ON media (content_hash);
+
+-- Images used to be arbitrary URLs which cannot be mapped onto uploaded media. They are
+-- kept aside, no longer read by the application, until they have been reviewed or
+-- imported. Nothing drops the column yet: that takes a new migration once it is unused.
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS media_ids uuid[] NOT NULL DEFAULT '{}';
+ALTER TABLE posts RENAME COLUMN images TO legacy_images;
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS avatar_id uuid REFERENCES media (media_id) ON DELETE SET NULL DEFAULT NULL;
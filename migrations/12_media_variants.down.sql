@@ -0,0 +1,9 @@
+DROP TABLE IF EXISTS media_variants;
+
+DROP INDEX IF EXISTS media_pending_idx;
+
+ALTER TABLE media
+    DROP COLUMN IF EXISTS status,
+    DROP COLUMN IF EXISTS width,
+    DROP COLUMN IF EXISTS height,
+    DROP COLUMN IF EXISTS blurhash;
@@ -0,0 +1,19 @@
+ALTER TABLE media
+    ADD COLUMN IF NOT EXISTS status   varchar(16) NOT NULL DEFAULT 'pending',
+    ADD COLUMN IF NOT EXISTS width    integer              DEFAULT NULL,
+    ADD COLUMN IF NOT EXISTS height   integer              DEFAULT NULL,
+    ADD COLUMN IF NOT EXISTS blurhash varchar(64)          DEFAULT NULL;
+
+CREATE INDEX IF NOT EXISTS media_pending_idx ON media (created_at) WHERE status = 'pending';
+
+CREATE TABLE IF NOT EXISTS media_variants
+(
+    media_id     uuid        NOT NULL REFERENCES media (media_id) ON DELETE CASCADE,
+    name         varchar(16) NOT NULL,
+    content_hash char(64)    NOT NULL,
+    content_type varchar(64) NOT NULL,
+    size         bigint      NOT NULL,
+    width        integer     NOT NULL,
+    height       integer     NOT NULL,
+    PRIMARY KEY (media_id, name)
+);
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS posts_search_vector_idx;
+DROP INDEX IF EXISTS channels_search_vector_idx;
+DROP INDEX IF EXISTS users_search_vector_idx;
+
+ALTER TABLE posts DROP COLUMN IF EXISTS search_vector;
+ALTER TABLE channels DROP COLUMN IF EXISTS search_vector;
+ALTER TABLE users DROP COLUMN IF EXISTS search_vector;
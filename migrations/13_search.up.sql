@@ -0,0 +1,22 @@
+-- The 'simple' configuration neither stems nor drops stop words, which suits
+-- usernames and mixed-language content and keeps prefix matching predictable.
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+        setweight(to_tsvector('simple', username), 'A') ||
+        setweight(to_tsvector('simple', coalesce(account_description, '')), 'C')
+        ) STORED;
+
+ALTER TABLE channels
+    ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+        setweight(to_tsvector('simple', title), 'A') ||
+        setweight(to_tsvector('simple', coalesce(description, '')), 'B')
+        ) STORED;
+
+ALTER TABLE posts
+    ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+        to_tsvector('simple', coalesce(content, ''))
+        ) STORED;
+
+CREATE INDEX IF NOT EXISTS users_search_vector_idx ON users USING gin (search_vector);
+CREATE INDEX IF NOT EXISTS channels_search_vector_idx ON channels USING gin (search_vector);
+CREATE INDEX IF NOT EXISTS posts_search_vector_idx ON posts USING gin (search_vector);
@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS mentions;
+DROP TABLE IF EXISTS post_hashtags;
+DROP TABLE IF EXISTS hashtags;
+
+ALTER TABLE comments DROP COLUMN IF EXISTS entities;
+ALTER TABLE posts DROP COLUMN IF EXISTS entities;
@@ -0,0 +1,39 @@
+-- entities keep the hashtags and mentions found in the content, with their offsets,
+-- as rendered to clients. The tables below index them for lookups.
+ALTER TABLE posts
+    ADD COLUMN IF NOT EXISTS entities jsonb NOT NULL DEFAULT '[]';
+
+ALTER TABLE comments
+    ADD COLUMN IF NOT EXISTS entities jsonb NOT NULL DEFAULT '[]';
+
+CREATE TABLE IF NOT EXISTS hashtags
+(
+    hashtag_id uuid PRIMARY KEY NOT NULL DEFAULT uuid_generate_v4(),
+    name       varchar(64)      NOT NULL UNIQUE
+);
+
+-- created_at is the post creation time, copied so that posts by hashtag and
+-- trending hashtags are served from the indexes below without touching posts.
+CREATE TABLE IF NOT EXISTS post_hashtags
+(
+    post_id    uuid      NOT NULL REFERENCES posts (post_id) ON DELETE CASCADE,
+    hashtag_id uuid      NOT NULL REFERENCES hashtags (hashtag_id) ON DELETE CASCADE,
+    created_at timestamp NOT NULL,
+    PRIMARY KEY (post_id, hashtag_id)
+);
+
+CREATE INDEX IF NOT EXISTS post_hashtags_hashtag_idx ON post_hashtags (hashtag_id, created_at DESC, post_id DESC);
+CREATE INDEX IF NOT EXISTS post_hashtags_created_at_idx ON post_hashtags (created_at);
+
+-- A mention is made either in the post itself (comment_id is NULL) or in one of its comments.
+CREATE TABLE IF NOT EXISTS mentions
+(
+    post_id    uuid      NOT NULL REFERENCES posts (post_id) ON DELETE CASCADE,
+    comment_id uuid REFERENCES comments (comment_id) ON DELETE CASCADE DEFAULT NULL,
+    user_id    uuid      NOT NULL REFERENCES users (user_id) ON DELETE CASCADE,
+    created_at timestamp NOT NULL DEFAULT now()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS mentions_post_user_idx ON mentions (post_id, user_id) WHERE comment_id IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS mentions_comment_user_idx ON mentions (comment_id, user_id) WHERE comment_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS mentions_user_idx ON mentions (user_id, created_at DESC);
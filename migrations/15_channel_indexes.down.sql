@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS channels_user_id_created_at_idx;
+DROP INDEX IF EXISTS channels_created_at_idx;
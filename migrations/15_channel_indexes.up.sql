@@ -0,0 +1,2 @@
+CREATE INDEX IF NOT EXISTS channels_created_at_idx ON channels (created_at DESC, channel_id DESC);
+CREATE INDEX IF NOT EXISTS channels_user_id_created_at_idx ON channels (user_id, created_at DESC, channel_id DESC);
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS realtime_events
+(
+    event_id   bigserial PRIMARY KEY NOT NULL,
+    type       varchar(32)           NOT NULL,
+    payload    jsonb                 NOT NULL,
+    user_ids   uuid[]                NOT NULL DEFAULT '{}',
+    channel_id uuid                           DEFAULT NULL,
+    created_at timestamp             NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS realtime_events_created_at_idx ON realtime_events (created_at);
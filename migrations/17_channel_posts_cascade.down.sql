@@ -0,0 +1,3 @@
+ALTER TABLE posts
+    DROP CONSTRAINT IF EXISTS posts_channel_id_fkey,
+    ADD CONSTRAINT posts_channel_id_fkey FOREIGN KEY (channel_id) REFERENCES channels (channel_id);
@@ -0,0 +1,5 @@
+-- Posts belong to their channel and go away with it; without an action, deleting a
+-- channel that has posts failed on the foreign key.
+ALTER TABLE posts
+    DROP CONSTRAINT IF EXISTS posts_channel_id_fkey,
+    ADD CONSTRAINT posts_channel_id_fkey FOREIGN KEY (channel_id) REFERENCES channels (channel_id) ON DELETE CASCADE;
@@ -0,0 +1,3 @@
+-- Usernames were only checked for uniqueness before writing, so two concurrent requests
+-- could both claim one. Duplicates left by such a race have to be renamed before this runs.
+CREATE UNIQUE INDEX IF NOT EXISTS users_username_idx ON users (username);
@@ -0,0 +1,12 @@
+DROP INDEX IF EXISTS likes_user_id_idx;
+
+ALTER TABLE likes
+    DROP CONSTRAINT IF EXISTS likes_pkey,
+    DROP CONSTRAINT IF EXISTS likes_post_id_fkey;
+
+DELETE FROM likes a USING likes b WHERE a.post_id = b.post_id AND a.ctid > b.ctid;
+
+ALTER TABLE likes
+    DROP COLUMN IF EXISTS created_at,
+    ADD PRIMARY KEY (post_id),
+    ADD CONSTRAINT likes_post_id_fkey FOREIGN KEY (post_id) REFERENCES posts (post_id);
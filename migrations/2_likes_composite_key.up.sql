@@ -0,0 +1,10 @@
+ALTER TABLE likes
+    DROP CONSTRAINT IF EXISTS likes_pkey,
+    DROP CONSTRAINT IF EXISTS likes_post_id_fkey;
+
+ALTER TABLE likes
+    ADD COLUMN IF NOT EXISTS created_at timestamp NOT NULL DEFAULT now(),
+    ADD PRIMARY KEY (post_id, user_id),
+    ADD CONSTRAINT likes_post_id_fkey FOREIGN KEY (post_id) REFERENCES posts (post_id) ON DELETE CASCADE;
+
+CREATE INDEX IF NOT EXISTS likes_user_id_idx ON likes (user_id);
@@ -0,0 +1,2 @@
+-- Fails while hashed passwords are stored: they cannot be narrowed back without losing data.
+ALTER TABLE users ALTER COLUMN password TYPE varchar(32);
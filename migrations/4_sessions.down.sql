@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS tokens
+(
+    user_id       uuid PRIMARY KEY NOT NULL REFERENCES users (user_id),
+    refresh_token text             NOT NULL
+);
+
+INSERT INTO tokens (user_id, refresh_token)
+SELECT DISTINCT ON (user_id) user_id, refresh_token
+FROM sessions
+ORDER BY user_id, last_used_at DESC;
+
+DROP TABLE IF EXISTS sessions;
@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS sessions
+(
+    session_id    uuid PRIMARY KEY NOT NULL DEFAULT uuid_generate_v4(),
+    user_id       uuid             NOT NULL REFERENCES users (user_id) ON DELETE CASCADE,
+    refresh_token text             NOT NULL,
+    user_agent    text             NOT NULL DEFAULT '',
+    ip            varchar(45)      NOT NULL DEFAULT '',
+    created_at    timestamp        NOT NULL DEFAULT now(),
+    last_used_at  timestamp        NOT NULL DEFAULT now(),
+    expires_at    timestamp        NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS sessions_user_id_idx ON sessions (user_id);
+CREATE UNIQUE INDEX IF NOT EXISTS sessions_refresh_token_idx ON sessions (refresh_token);
+
+INSERT INTO sessions (user_id, refresh_token, expires_at)
+SELECT user_id, refresh_token, now() + interval '30 days'
+FROM tokens;
+
+DROP TABLE IF EXISTS tokens;
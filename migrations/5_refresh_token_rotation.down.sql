@@ -0,0 +1,8 @@
+-- Only hashes are stored, so the previous plaintext tokens cannot be restored:
+-- every session is dropped and users have to sign in again.
+DELETE FROM sessions;
+
+ALTER TABLE sessions ADD COLUMN IF NOT EXISTS refresh_token text NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS sessions_refresh_token_idx ON sessions (refresh_token);
+
+DROP TABLE IF EXISTS refresh_tokens;
@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS refresh_tokens
+(
+    token_hash char(64) PRIMARY KEY NOT NULL,
+    family_id  uuid                 NOT NULL REFERENCES sessions (session_id) ON DELETE CASCADE,
+    created_at timestamp            NOT NULL DEFAULT now(),
+    rotated_at timestamp                     DEFAULT NULL
+);
+
+CREATE INDEX IF NOT EXISTS refresh_tokens_family_id_idx ON refresh_tokens (family_id);
+
+INSERT INTO refresh_tokens (token_hash, family_id)
+SELECT encode(sha256(convert_to(refresh_token, 'UTF8')), 'hex'), session_id
+FROM sessions;
+
+DROP INDEX IF EXISTS sessions_refresh_token_idx;
+ALTER TABLE sessions DROP COLUMN IF EXISTS refresh_token;
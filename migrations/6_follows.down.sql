@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS channel_subscriptions;
+DROP TABLE IF EXISTS follows;
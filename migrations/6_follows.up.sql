@@ -0,0 +1,22 @@
+CREATE TABLE IF NOT EXISTS follows
+(
+    follower_id uuid      NOT NULL REFERENCES users (user_id) ON DELETE CASCADE,
+    followee_id uuid      NOT NULL REFERENCES users (user_id) ON DELETE CASCADE,
+    created_at  timestamp NOT NULL DEFAULT now(),
+    PRIMARY KEY (follower_id, followee_id),
+    CHECK (follower_id <> followee_id)
+);
+
+CREATE INDEX IF NOT EXISTS follows_followee_idx ON follows (followee_id, created_at DESC, follower_id DESC);
+CREATE INDEX IF NOT EXISTS follows_follower_idx ON follows (follower_id, created_at DESC, followee_id DESC);
+
+CREATE TABLE IF NOT EXISTS channel_subscriptions
+(
+    user_id    uuid      NOT NULL REFERENCES users (user_id) ON DELETE CASCADE,
+    channel_id uuid      NOT NULL REFERENCES channels (channel_id) ON DELETE CASCADE,
+    created_at timestamp NOT NULL DEFAULT now(),
+    PRIMARY KEY (user_id, channel_id)
+);
+
+CREATE INDEX IF NOT EXISTS channel_subscriptions_channel_idx
+    ON channel_subscriptions (channel_id, created_at DESC, user_id DESC);
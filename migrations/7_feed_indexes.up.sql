@@ -0,0 +1,2 @@
+CREATE INDEX IF NOT EXISTS posts_user_id_created_at_idx ON posts (user_id, created_at DESC, post_id DESC);
+CREATE INDEX IF NOT EXISTS posts_channel_id_created_at_idx ON posts (channel_id, created_at DESC, post_id DESC);
@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS comments
+(
+    comment_id        uuid PRIMARY KEY NOT NULL DEFAULT uuid_generate_v4(),
+    post_id           uuid             NOT NULL REFERENCES posts (post_id) ON DELETE CASCADE,
+    user_id           uuid             NOT NULL REFERENCES users (user_id) ON DELETE CASCADE,
+    parent_comment_id uuid REFERENCES comments (comment_id) ON DELETE CASCADE DEFAULT NULL,
+    content           text             NOT NULL,
+    created_at        timestamp        NOT NULL DEFAULT now(),
+    updated_at        timestamp                 DEFAULT NULL,
+    deleted_at        timestamp                 DEFAULT NULL
+);
+
+CREATE INDEX IF NOT EXISTS comments_post_id_idx ON comments (post_id, created_at, comment_id);
+CREATE INDEX IF NOT EXISTS comments_parent_comment_id_idx ON comments (parent_comment_id);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS conversation_members;
+DROP TABLE IF EXISTS messages;
+DROP TABLE IF EXISTS conversations;
@@ -0,0 +1,32 @@
+CREATE TABLE IF NOT EXISTS conversations
+(
+    conversation_id uuid PRIMARY KEY NOT NULL DEFAULT uuid_generate_v4(),
+    direct_key      text UNIQUE               DEFAULT NULL,
+    created_at      timestamp        NOT NULL DEFAULT now(),
+    last_message_at timestamp                 DEFAULT NULL
+);
+
+CREATE INDEX IF NOT EXISTS conversations_activity_idx
+    ON conversations ((coalesce(last_message_at, created_at)) DESC, conversation_id DESC);
+
+CREATE TABLE IF NOT EXISTS messages
+(
+    message_id      uuid PRIMARY KEY NOT NULL DEFAULT uuid_generate_v4(),
+    conversation_id uuid             NOT NULL REFERENCES conversations (conversation_id) ON DELETE CASCADE,
+    user_id         uuid             NOT NULL REFERENCES users (user_id) ON DELETE CASCADE,
+    content         text             NOT NULL,
+    created_at      timestamp        NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS messages_conversation_idx ON messages (conversation_id, created_at DESC, message_id DESC);
+
+CREATE TABLE IF NOT EXISTS conversation_members
+(
+    conversation_id      uuid      NOT NULL REFERENCES conversations (conversation_id) ON DELETE CASCADE,
+    user_id              uuid      NOT NULL REFERENCES users (user_id) ON DELETE CASCADE,
+    joined_at            timestamp NOT NULL DEFAULT now(),
+    last_read_message_id uuid REFERENCES messages (message_id) ON DELETE SET NULL DEFAULT NULL,
+    PRIMARY KEY (conversation_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS conversation_members_user_idx ON conversation_members (user_id);
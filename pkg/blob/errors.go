@@ -0,0 +1,8 @@
+package blob
+
+import "errors"
+
+var (
+	NotFoundBlobErr = errors.New("no blob found")
+	InvalidKeyErr   = errors.New("invalid blob key")
+)
@@ -0,0 +1,117 @@
+package blob
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// LocalStore keeps blobs on the local filesystem, fanned out into two levels of
+// directories by the first characters of the key.
+type LocalStore struct {
+	root string
+}
+
+func NewLocalStore(root string) (*LocalStore, error) {
+	err := os.MkdirAll(root, 0o755)
+	if err != nil {
+		return nil, errors.Wrap(err, "NewLocalStore")
+	}
+
+	return &LocalStore{root: root}, nil
+}
+
+// Put writes the blob to a temporary file first, so readers never see a partial blob.
+func (s *LocalStore) Put(_ context.Context, key string, r io.Reader, _ int64, _ string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return errors.Wrap(err, "LocalStore.Put")
+	}
+
+	err = os.MkdirAll(filepath.Dir(path), 0o755)
+	if err != nil {
+		return errors.Wrap(err, "LocalStore.Put")
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return errors.Wrap(err, "LocalStore.Put")
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = io.Copy(tmp, r)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return errors.Wrap(err, "LocalStore.Put")
+	}
+
+	err = os.Rename(tmp.Name(), path)
+	if err != nil {
+		return errors.Wrap(err, "LocalStore.Put")
+	}
+
+	return nil
+}
+
+func (s *LocalStore) Get(_ context.Context, key string) (io.ReadCloser, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, errors.Wrap(err, "LocalStore.Get")
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		switch {
+		case errors.Is(err, os.ErrNotExist):
+			return nil, errors.Wrap(NotFoundBlobErr, "LocalStore.Get")
+		default:
+			return nil, errors.Wrap(err, "LocalStore.Get")
+		}
+	}
+
+	return file, nil
+}
+
+func (s *LocalStore) Exists(_ context.Context, key string) (bool, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return false, errors.Wrap(err, "LocalStore.Exists")
+	}
+
+	_, err = os.Stat(path)
+	switch {
+	case err == nil:
+		return true, nil
+	case errors.Is(err, os.ErrNotExist):
+		return false, nil
+	default:
+		return false, errors.Wrap(err, "LocalStore.Exists")
+	}
+}
+
+func (s *LocalStore) Delete(_ context.Context, key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return errors.Wrap(err, "LocalStore.Delete")
+	}
+
+	err = os.Remove(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return errors.Wrap(err, "LocalStore.Delete")
+	}
+
+	return nil
+}
+
+func (s *LocalStore) path(key string) (string, error) {
+	if len(key) < 4 || strings.ContainsAny(key, `/\.`) {
+		return "", InvalidKeyErr
+	}
+
+	return filepath.Join(s.root, key[:2], key[2:4], key), nil
+}
@@ -0,0 +1,90 @@
+package blob
+
+import (
+	"context"
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+	"github.com/petrkoval/social-network-back/internal/config"
+	"github.com/pkg/errors"
+	"io"
+)
+
+const noSuchKeyCode = "NoSuchKey"
+
+// S3Store keeps blobs in a bucket of any S3-compatible storage, e.g. a local MinIO.
+type S3Store struct {
+	client *minio.Client
+	bucket string
+}
+
+func NewS3Store(c *config.S3Config) (*S3Store, error) {
+	if c == nil {
+		return nil, errors.New("NewS3Store: s3 storage is not configured")
+	}
+
+	client, err := minio.New(c.Endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(c.AccessKey, c.SecretKey, ""),
+		Secure: c.UseSSL,
+		Region: c.Region,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "NewS3Store")
+	}
+
+	return &S3Store{
+		client: client,
+		bucket: c.Bucket,
+	}, nil
+}
+
+func (s *S3Store) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	_, err := s.client.PutObject(ctx, s.bucket, key, r, size, minio.PutObjectOptions{ContentType: contentType})
+	if err != nil {
+		return errors.Wrap(err, "S3Store.Put")
+	}
+
+	return nil
+}
+
+func (s *S3Store) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	object, err := s.client.GetObject(ctx, s.bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "S3Store.Get")
+	}
+
+	// GetObject is lazy, so a missing object only shows up on the first request.
+	_, err = object.Stat()
+	if err != nil {
+		_ = object.Close()
+
+		switch {
+		case minio.ToErrorResponse(err).Code == noSuchKeyCode:
+			return nil, errors.Wrap(NotFoundBlobErr, "S3Store.Get")
+		default:
+			return nil, errors.Wrap(err, "S3Store.Get")
+		}
+	}
+
+	return object, nil
+}
+
+func (s *S3Store) Exists(ctx context.Context, key string) (bool, error) {
+	_, err := s.client.StatObject(ctx, s.bucket, key, minio.StatObjectOptions{})
+	switch {
+	case err == nil:
+		return true, nil
+	case minio.ToErrorResponse(err).Code == noSuchKeyCode:
+		return false, nil
+	default:
+		return false, errors.Wrap(err, "S3Store.Exists")
+	}
+}
+
+func (s *S3Store) Delete(ctx context.Context, key string) error {
+	err := s.client.RemoveObject(ctx, s.bucket, key, minio.RemoveObjectOptions{})
+	if err != nil {
+		return errors.Wrap(err, "S3Store.Delete")
+	}
+
+	return nil
+}
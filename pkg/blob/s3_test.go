@@ -0,0 +1,169 @@
+package blob
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/petrkoval/social-network-back/internal/config"
+)
+
+const testBucket = "media"
+
+// fakeS3 implements the few path-style object requests S3Store makes. Signatures are
+// not verified.
+type fakeS3 struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	bucket, key, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	if bucket != testBucket || key == "" {
+		writeS3Error(w, http.StatusNotFound, "NoSuchBucket")
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch r.Method {
+	case http.MethodPut:
+		data, err := readS3Body(r)
+		if err != nil {
+			writeS3Error(w, http.StatusBadRequest, "IncompleteBody")
+			return
+		}
+
+		f.objects[key] = data
+		w.Header().Set("ETag", `"etag"`)
+		w.WriteHeader(http.StatusOK)
+	case http.MethodGet, http.MethodHead:
+		data, ok := f.objects[key]
+		if !ok {
+			writeS3Error(w, http.StatusNotFound, "NoSuchKey")
+			return
+		}
+
+		w.Header().Set("ETag", `"etag"`)
+		w.Header().Set("Last-Modified", time.Now().UTC().Format(http.TimeFormat))
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+		w.WriteHeader(http.StatusOK)
+		if r.Method == http.MethodGet {
+			_, _ = w.Write(data)
+		}
+	case http.MethodDelete:
+		delete(f.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeS3Error(w, http.StatusMethodNotAllowed, "MethodNotAllowed")
+	}
+}
+
+// readS3Body decodes the aws-chunked encoding the client uses over plain HTTP.
+func readS3Body(r *http.Request) ([]byte, error) {
+	if !strings.HasPrefix(r.Header.Get("X-Amz-Content-Sha256"), "STREAMING-") {
+		return io.ReadAll(r.Body)
+	}
+
+	var (
+		data   []byte
+		reader = bufio.NewReader(r.Body)
+	)
+
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+
+		sizeHex, _, _ := strings.Cut(strings.TrimSpace(line), ";")
+		size, err := strconv.ParseInt(sizeHex, 16, 64)
+		if err != nil {
+			return nil, err
+		}
+		if size == 0 {
+			return data, nil
+		}
+
+		chunk := make([]byte, size+2)
+		_, err = io.ReadFull(reader, chunk)
+		if err != nil {
+			return nil, err
+		}
+		data = append(data, chunk[:size]...)
+	}
+}
+
+func writeS3Error(w http.ResponseWriter, status int, code string) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	_, _ = fmt.Fprintf(w, `<?xml version="1.0" encoding="UTF-8"?><Error><Code>%s</Code><Message>%s</Message></Error>`, code, code)
+}
+
+func TestS3Store(t *testing.T) {
+	server := httptest.NewServer(&fakeS3{objects: make(map[string][]byte)})
+	defer server.Close()
+
+	endpoint, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := NewS3Store(&config.S3Config{
+		Endpoint:  endpoint.Host,
+		Region:    "us-east-1",
+		Bucket:    testBucket,
+		AccessKey: "access",
+		SecretKey: "secret",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testStore(t, s)
+}
+
+// TestS3StoreEndpoint runs the suite against real S3-compatible storage, e.g. a local
+// MinIO, when BLOB_TEST_S3_ENDPOINT is set. The bucket must exist; the test keys are
+// deleted afterwards.
+func TestS3StoreEndpoint(t *testing.T) {
+	endpoint := os.Getenv("BLOB_TEST_S3_ENDPOINT")
+	if endpoint == "" {
+		t.Skip("BLOB_TEST_S3_ENDPOINT is not set")
+	}
+
+	s, err := NewS3Store(&config.S3Config{
+		Endpoint:  endpoint,
+		Region:    os.Getenv("BLOB_TEST_S3_REGION"),
+		Bucket:    os.Getenv("BLOB_TEST_S3_BUCKET"),
+		AccessKey: os.Getenv("BLOB_TEST_S3_ACCESS_KEY"),
+		SecretKey: os.Getenv("BLOB_TEST_S3_SECRET_KEY"),
+		UseSSL:    os.Getenv("BLOB_TEST_S3_USE_SSL") == "true",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, key := range []string{testKey, otherKey} {
+		_ = s.Delete(context.Background(), key)
+	}
+	t.Cleanup(func() {
+		for _, key := range []string{testKey, otherKey} {
+			_ = s.Delete(context.Background(), key)
+		}
+	})
+
+	testStore(t, s)
+}
@@ -0,0 +1,155 @@
+package blob
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+// store is the behaviour every blob store must share, whatever keeps the blobs.
+type store interface {
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Exists(ctx context.Context, key string) (bool, error)
+	Delete(ctx context.Context, key string) error
+}
+
+const (
+	testKey  = "3f786850e387550fdab836ed7e6dc881de23001b"
+	otherKey = "89e6c98d92887913cadf06b2adb97f26cde4849b"
+)
+
+func put(t *testing.T, s store, key string, data []byte) {
+	t.Helper()
+
+	err := s.Put(context.Background(), key, bytes.NewReader(data), int64(len(data)), "image/png")
+	if err != nil {
+		t.Fatalf("put %s: %v", key, err)
+	}
+}
+
+func assertContent(t *testing.T, s store, key string, want []byte) {
+	t.Helper()
+
+	r, err := s.Get(context.Background(), key)
+	if err != nil {
+		t.Fatalf("get %s: %v", key, err)
+	}
+	defer r.Close()
+
+	got, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("read %s: %v", key, err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Fatalf("%s holds %q, want %q", key, got, want)
+	}
+}
+
+func assertExists(t *testing.T, s store, key string, want bool) {
+	t.Helper()
+
+	exists, err := s.Exists(context.Background(), key)
+	if err != nil {
+		t.Fatalf("exists %s: %v", key, err)
+	}
+	if exists != want {
+		t.Fatalf("%s exists: %t, want %t", key, exists, want)
+	}
+}
+
+// testStore runs the shared suite against an empty store.
+func testStore(t *testing.T, s store) {
+	ctx := context.Background()
+
+	t.Run("missing", func(t *testing.T) {
+		assertExists(t, s, testKey, false)
+
+		_, err := s.Get(ctx, testKey)
+		if !errors.Is(err, NotFoundBlobErr) {
+			t.Fatalf("got %v, want NotFoundBlobErr", err)
+		}
+	})
+
+	t.Run("put and get", func(t *testing.T) {
+		put(t, s, testKey, []byte("first"))
+
+		assertExists(t, s, testKey, true)
+		assertContent(t, s, testKey, []byte("first"))
+		assertExists(t, s, otherKey, false)
+	})
+
+	t.Run("overwrite", func(t *testing.T) {
+		put(t, s, testKey, []byte("second"))
+
+		assertContent(t, s, testKey, []byte("second"))
+	})
+
+	t.Run("large", func(t *testing.T) {
+		data := bytes.Repeat([]byte("0123456789abcdef"), 64<<10)
+		put(t, s, otherKey, data)
+
+		assertContent(t, s, otherKey, data)
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		put(t, s, otherKey, nil)
+
+		assertExists(t, s, otherKey, true)
+		assertContent(t, s, otherKey, nil)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		err := s.Delete(ctx, testKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		assertExists(t, s, testKey, false)
+		assertExists(t, s, otherKey, true)
+
+		_, err = s.Get(ctx, testKey)
+		if !errors.Is(err, NotFoundBlobErr) {
+			t.Fatalf("got %v, want NotFoundBlobErr", err)
+		}
+	})
+
+	t.Run("delete missing", func(t *testing.T) {
+		err := s.Delete(ctx, testKey)
+		if err != nil {
+			t.Fatalf("deleting a missing blob: %v", err)
+		}
+	})
+}
+
+func TestLocalStore(t *testing.T) {
+	s, err := NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testStore(t, s)
+}
+
+func TestLocalStoreKeys(t *testing.T) {
+	s, err := NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, key := range []string{"", "abc", "../../etc/passwd", "ab/cdef", `ab\cdef`, "abcd.tmp"} {
+		err := s.Put(context.Background(), key, strings.NewReader("data"), 4, "text/plain")
+		if !errors.Is(err, InvalidKeyErr) {
+			t.Errorf("put %q: got %v, want InvalidKeyErr", key, err)
+		}
+
+		_, err = s.Exists(context.Background(), key)
+		if !errors.Is(err, InvalidKeyErr) {
+			t.Errorf("exists %q: got %v, want InvalidKeyErr", key, err)
+		}
+	}
+}
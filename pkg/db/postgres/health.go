@@ -0,0 +1,73 @@
+package postgres
+
+import (
+	"context"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/petrkoval/social-network-back/migrations"
+	"github.com/pkg/errors"
+)
+
+// PoolChecker reports whether the database can be reached.
+type PoolChecker struct {
+	pool *pgxpool.Pool
+}
+
+func NewPoolChecker(pool *pgxpool.Pool) *PoolChecker {
+	return &PoolChecker{pool: pool}
+}
+
+func (c *PoolChecker) Name() string {
+	return "database"
+}
+
+func (c *PoolChecker) Check(ctx context.Context) error {
+	return errors.Wrap(c.pool.Ping(ctx), "PoolChecker.Check")
+}
+
+// MigrationChecker reports whether the database schema has at least the latest migration
+// embedded in the binary. A newer schema is fine: during a rolling deploy the instances
+// of the previous release keep serving after the new one has migrated. It reads the
+// version table directly instead of going through the Migrator, which would take the
+// migration lock.
+type MigrationChecker struct {
+	pool   *pgxpool.Pool
+	latest uint
+}
+
+func NewMigrationChecker(pool *pgxpool.Pool) (*MigrationChecker, error) {
+	latest, err := latestMigration(migrations.FS)
+	if err != nil {
+		return nil, errors.Wrap(err, "NewMigrationChecker")
+	}
+
+	return &MigrationChecker{pool: pool, latest: latest}, nil
+}
+
+func (c *MigrationChecker) Name() string {
+	return "migrations"
+}
+
+func (c *MigrationChecker) Check(ctx context.Context) error {
+	var (
+		version int64
+		dirty   bool
+	)
+
+	err := c.pool.QueryRow(ctx, `SELECT version, dirty FROM schema_migrations LIMIT 1`).Scan(&version, &dirty)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return errors.New("MigrationChecker.Check: no migrations applied")
+	}
+	if err != nil {
+		return errors.Wrap(err, "MigrationChecker.Check")
+	}
+
+	if dirty {
+		return errors.Errorf("MigrationChecker.Check: migration %d is dirty", version)
+	}
+	if version < int64(c.latest) {
+		return errors.Errorf("MigrationChecker.Check: database is at version %d, expected %d", version, c.latest)
+	}
+
+	return nil
+}
@@ -0,0 +1,133 @@
+package postgres
+
+import (
+	"github.com/golang-migrate/migrate/v4"
+	pgxmigrate "github.com/golang-migrate/migrate/v4/database/pgx/v5"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jackc/pgx/v5/stdlib"
+	"github.com/petrkoval/social-network-back/migrations"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+	"io/fs"
+	"strconv"
+	"strings"
+)
+
+// Migrator applies the migrations embedded in the binary. Every command holds a
+// PostgreSQL advisory lock while it runs, so instances starting at the same time
+// apply the migrations one after another instead of racing.
+type Migrator struct {
+	migrate *migrate.Migrate
+	latest  uint
+}
+
+// MigrationStatus is the version the database is at and the latest embedded one.
+// Dirty means a migration failed halfway and has to be fixed and forced.
+type MigrationStatus struct {
+	Version uint `json:"version"`
+	Latest  uint `json:"latest"`
+	Dirty   bool `json:"dirty"`
+}
+
+func NewMigrator(pool *pgxpool.Pool, l *zerolog.Logger) (*Migrator, error) {
+	source, err := iofs.New(migrations.FS, ".")
+	if err != nil {
+		return nil, errors.Wrap(err, "NewMigrator")
+	}
+
+	latest, err := latestMigration(migrations.FS)
+	if err != nil {
+		return nil, errors.Wrap(err, "NewMigrator")
+	}
+
+	driver, err := pgxmigrate.WithInstance(stdlib.OpenDBFromPool(pool), &pgxmigrate.Config{})
+	if err != nil {
+		return nil, errors.Wrap(err, "NewMigrator")
+	}
+
+	m, err := migrate.NewWithInstance("iofs", source, "pgx", driver)
+	if err != nil {
+		return nil, errors.Wrap(err, "NewMigrator")
+	}
+	m.Log = &zerologMigrateLogger{logger: l}
+
+	return &Migrator{migrate: m, latest: latest}, nil
+}
+
+// Up applies all migrations that have not been applied yet.
+func (m *Migrator) Up() error {
+	err := m.migrate.Up()
+	if err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return errors.Wrap(err, "Migrator.Up")
+	}
+
+	return nil
+}
+
+// Down reverts the given number of the most recent migrations.
+func (m *Migrator) Down(steps int) error {
+	err := m.migrate.Steps(-steps)
+	if err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return errors.Wrap(err, "Migrator.Down")
+	}
+
+	return nil
+}
+
+// Force marks the database as being at version without running anything, clearing the dirty flag.
+func (m *Migrator) Force(version int) error {
+	return errors.Wrap(m.migrate.Force(version), "Migrator.Force")
+}
+
+func (m *Migrator) Status() (*MigrationStatus, error) {
+	version, dirty, err := m.migrate.Version()
+	if err != nil && !errors.Is(err, migrate.ErrNilVersion) {
+		return nil, errors.Wrap(err, "Migrator.Status")
+	}
+
+	return &MigrationStatus{Version: version, Latest: m.latest, Dirty: dirty}, nil
+}
+
+// Close releases the connection held by the migrator; the pool stays open.
+func (m *Migrator) Close() error {
+	sourceErr, dbErr := m.migrate.Close()
+	if sourceErr != nil {
+		return errors.Wrap(sourceErr, "Migrator.Close")
+	}
+
+	return errors.Wrap(dbErr, "Migrator.Close")
+}
+
+// latestMigration returns the highest version among the N_name.up.sql files.
+func latestMigration(fsys fs.FS) (uint, error) {
+	names, err := fs.Glob(fsys, "*.up.sql")
+	if err != nil {
+		return 0, err
+	}
+
+	var latest uint
+	for _, name := range names {
+		prefix, _, _ := strings.Cut(name, "_")
+
+		version, err := strconv.ParseUint(prefix, 10, 64)
+		if err != nil {
+			return 0, errors.Errorf("migration %s has no version", name)
+		}
+		latest = max(latest, uint(version))
+	}
+
+	return latest, nil
+}
+
+type zerologMigrateLogger struct {
+	logger *zerolog.Logger
+}
+
+func (z *zerologMigrateLogger) Printf(format string, v ...interface{}) {
+	z.logger.Info().Msgf(strings.TrimSuffix(format, "\n"), v...)
+}
+
+func (z *zerologMigrateLogger) Verbose() bool {
+	return false
+}
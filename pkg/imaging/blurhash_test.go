@@ -0,0 +1,53 @@
+package imaging
+
+import (
+	"image"
+	"testing"
+)
+
+func solid(value uint8) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, 8, 8))
+	for i := range img.Pix {
+		img.Pix[i] = value
+	}
+
+	return img
+}
+
+func TestBlurhash(t *testing.T) {
+	tests := []struct {
+		name        string
+		img         *image.NRGBA
+		x, y        int
+		sizeFlag    string
+		averageHash string
+	}{
+		{"white 4x3", solid(255), 4, 3, "L", "TSUA"},
+		{"black 4x3", solid(0), 4, 3, "L", "0000"},
+		{"white 1x1", solid(255), 1, 1, "0", "TSUA"},
+		{"white 9x9", solid(255), 9, 9, "|", "TSUA"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hash := Blurhash(tt.img, tt.x, tt.y)
+
+			if want := 4 + 2*tt.x*tt.y; len(hash) != want {
+				t.Fatalf("%q has %d characters, want %d", hash, len(hash), want)
+			}
+			if hash[:1] != tt.sizeFlag {
+				t.Errorf("size flag is %q, want %q", hash[:1], tt.sizeFlag)
+			}
+			if hash[2:6] != tt.averageHash {
+				t.Errorf("average color is %q, want %q", hash[2:6], tt.averageHash)
+			}
+			if again := Blurhash(tt.img, tt.x, tt.y); again != hash {
+				t.Errorf("not deterministic: %q and %q", hash, again)
+			}
+		})
+	}
+
+	if Blurhash(gradient(16, 8, 255), 4, 3) == Blurhash(solid(128), 4, 3) {
+		t.Error("gradient hashes like a solid image")
+	}
+}
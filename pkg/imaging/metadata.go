@@ -0,0 +1,366 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"github.com/pkg/errors"
+)
+
+var MalformedImageErr = errors.New("malformed image")
+
+// StripMetadata removes EXIF, XMP, IPTC and textual metadata from the image without
+// re-encoding it. Everything needed to display the image, such as ICC profiles and
+// GIF animation settings, is kept.
+func StripMetadata(data []byte, contentType string) ([]byte, error) {
+	switch contentType {
+	case "image/jpeg":
+		return stripJPEG(data)
+	case "image/png":
+		return stripPNG(data)
+	case "image/gif":
+		return stripGIF(data)
+	case "image/webp":
+		return stripWebP(data)
+	default:
+		return nil, errors.Wrapf(UnsupportedFormatErr, "StripMetadata: %s", contentType)
+	}
+}
+
+const (
+	jpegEOI  = 0xD9
+	jpegSOS  = 0xDA
+	jpegAPP1 = 0xE1 // EXIF and XMP
+	jpegAPPD = 0xED // Photoshop IPTC
+	jpegCOM  = 0xFE
+)
+
+// jpegSegment is a marker with its payload, data[start:end] in the image.
+type jpegSegment struct {
+	marker  byte
+	start   int
+	end     int
+	payload []byte
+}
+
+// nextJPEGSegment returns the segment at pos. Like the standard decoder, it skips
+// extraneous bytes before a marker, fill bytes and "\xff\x00". The start of scan
+// segment runs to the end of the data, as the entropy-coded data follows it.
+func nextJPEGSegment(data []byte, pos int) (jpegSegment, error) {
+	for {
+		for pos < len(data) && data[pos] != 0xFF {
+			pos++
+		}
+
+		start := pos
+		for pos < len(data) && data[pos] == 0xFF {
+			pos++
+		}
+		if pos >= len(data) {
+			return jpegSegment{}, MalformedImageErr
+		}
+
+		marker := data[pos]
+		pos++
+
+		switch {
+		case marker == 0x00:
+			continue
+		case marker == 0x01 || (marker >= 0xD0 && marker <= jpegEOI):
+			return jpegSegment{marker: marker, start: start, end: pos}, nil
+		case marker == jpegSOS:
+			return jpegSegment{marker: marker, start: start, end: len(data), payload: data[pos:]}, nil
+		}
+
+		if pos+2 > len(data) {
+			return jpegSegment{}, MalformedImageErr
+		}
+
+		// The length counts its own two bytes.
+		length := int(binary.BigEndian.Uint16(data[pos:]))
+		end := pos + length
+		if length < 2 || end > len(data) {
+			return jpegSegment{}, MalformedImageErr
+		}
+
+		return jpegSegment{marker: marker, start: start, end: end, payload: data[pos+2 : end]}, nil
+	}
+}
+
+func stripJPEG(data []byte) ([]byte, error) {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return nil, MalformedImageErr
+	}
+
+	out := bytes.NewBuffer(make([]byte, 0, len(data)))
+	out.Write(data[:2])
+
+	for pos := 2; ; {
+		segment, err := nextJPEGSegment(data, pos)
+		if err != nil {
+			return nil, err
+		}
+
+		if segment.marker != jpegAPP1 && segment.marker != jpegAPPD && segment.marker != jpegCOM {
+			out.Write(data[segment.start:segment.end])
+		}
+
+		if segment.marker == jpegSOS || segment.marker == jpegEOI {
+			return out.Bytes(), nil
+		}
+
+		pos = segment.end
+	}
+}
+
+var pngSignature = []byte("\x89PNG\r\n\x1a\n")
+
+var pngMetadataChunks = map[string]bool{
+	"eXIf": true,
+	"tEXt": true,
+	"zTXt": true,
+	"iTXt": true,
+	"tIME": true,
+}
+
+func stripPNG(data []byte) ([]byte, error) {
+	if !bytes.HasPrefix(data, pngSignature) {
+		return nil, MalformedImageErr
+	}
+
+	out := bytes.NewBuffer(make([]byte, 0, len(data)))
+	out.Write(pngSignature)
+
+	for pos := len(pngSignature); pos < len(data); {
+		if pos+8 > len(data) {
+			return nil, MalformedImageErr
+		}
+
+		length := int(binary.BigEndian.Uint32(data[pos:]))
+		chunkType := string(data[pos+4 : pos+8])
+
+		// length, type, data and CRC
+		end := pos + 12 + length
+		if length < 0 || end > len(data) {
+			return nil, MalformedImageErr
+		}
+
+		if !pngMetadataChunks[chunkType] {
+			out.Write(data[pos:end])
+		}
+
+		pos = end
+	}
+
+	return out.Bytes(), nil
+}
+
+const (
+	gifExtension = 0x21
+	gifImage     = 0x2C
+	gifTrailer   = 0x3B
+
+	gifCommentLabel     = 0xFE
+	gifApplicationLabel = 0xFF
+)
+
+// gifKeptApplications are the application extensions controlling animation.
+var gifKeptApplications = []string{"NETSCAPE2.0", "ANIMEXTS1.0"}
+
+func stripGIF(data []byte) ([]byte, error) {
+	if len(data) < 13 || !bytes.HasPrefix(data, []byte("GIF8")) {
+		return nil, MalformedImageErr
+	}
+
+	// Header, logical screen descriptor and the optional global color table.
+	pos := 13
+	if data[10]&0x80 != 0 {
+		pos += 3 << ((data[10] & 0x07) + 1)
+	}
+	if pos > len(data) {
+		return nil, MalformedImageErr
+	}
+
+	out := bytes.NewBuffer(make([]byte, 0, len(data)))
+	out.Write(data[:pos])
+
+	for pos < len(data) {
+		start := pos
+
+		switch data[pos] {
+		case gifTrailer:
+			out.WriteByte(gifTrailer)
+			return out.Bytes(), nil
+		case gifExtension:
+			if pos+2 > len(data) {
+				return nil, MalformedImageErr
+			}
+
+			label := data[pos+1]
+			end, err := skipGIFSubBlocks(data, pos+2)
+			if err != nil {
+				return nil, err
+			}
+
+			keep := label != gifCommentLabel
+			if label == gifApplicationLabel {
+				keep = false
+				for _, app := range gifKeptApplications {
+					if bytes.HasPrefix(data[pos+3:end], []byte(app)) {
+						keep = true
+					}
+				}
+			}
+
+			if keep {
+				out.Write(data[start:end])
+			}
+			pos = end
+		case gifImage:
+			if pos+11 > len(data) {
+				return nil, MalformedImageErr
+			}
+
+			// Image descriptor, the optional local color table and the LZW code size.
+			pos += 10
+			if data[start+9]&0x80 != 0 {
+				pos += 3 << ((data[start+9] & 0x07) + 1)
+			}
+			pos++
+
+			end, err := skipGIFSubBlocks(data, pos)
+			if err != nil {
+				return nil, err
+			}
+
+			out.Write(data[start:end])
+			pos = end
+		default:
+			return nil, MalformedImageErr
+		}
+	}
+
+	return nil, MalformedImageErr
+}
+
+// skipGIFSubBlocks returns the position right after the sub-blocks starting at pos.
+func skipGIFSubBlocks(data []byte, pos int) (int, error) {
+	for {
+		if pos >= len(data) {
+			return 0, MalformedImageErr
+		}
+
+		size := int(data[pos])
+		pos++
+		if size == 0 {
+			return pos, nil
+		}
+
+		pos += size
+	}
+}
+
+const (
+	webpXMPFlag  = 0x04
+	webpEXIFFlag = 0x08
+)
+
+func stripWebP(data []byte) ([]byte, error) {
+	if len(data) < 12 || string(data[:4]) != "RIFF" || string(data[8:12]) != "WEBP" {
+		return nil, MalformedImageErr
+	}
+
+	out := bytes.NewBuffer(make([]byte, 0, len(data)))
+	out.Write(data[:12])
+
+	for pos := 12; pos < len(data); {
+		if pos+8 > len(data) {
+			return nil, MalformedImageErr
+		}
+
+		fourCC := string(data[pos : pos+4])
+		size := int(binary.LittleEndian.Uint32(data[pos+4:]))
+
+		// Chunks are padded to an even size.
+		end := pos + 8 + size + size%2
+		if size < 0 || end > len(data) {
+			return nil, MalformedImageErr
+		}
+
+		switch fourCC {
+		case "EXIF", "XMP ":
+		case "VP8X":
+			chunk := bytes.Clone(data[pos:end])
+			if len(chunk) > 8 {
+				chunk[8] &^= webpEXIFFlag | webpXMPFlag
+			}
+			out.Write(chunk)
+		default:
+			out.Write(data[pos:end])
+		}
+
+		pos = end
+	}
+
+	result := out.Bytes()
+	binary.LittleEndian.PutUint32(result[4:], uint32(len(result)-8))
+
+	return result, nil
+}
+
+// jpegOrientation returns the EXIF orientation of a JPEG image, 1 when it has none.
+func jpegOrientation(data []byte) int {
+	for pos := 2; ; {
+		segment, err := nextJPEGSegment(data, pos)
+		if err != nil || segment.marker == jpegSOS || segment.marker == jpegEOI {
+			return 1
+		}
+
+		if segment.marker == jpegAPP1 && bytes.HasPrefix(segment.payload, []byte("Exif\x00\x00")) {
+			return exifOrientation(segment.payload[6:])
+		}
+
+		pos = segment.end
+	}
+}
+
+const exifOrientationTag = 0x0112
+
+func exifOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+
+	ifd := int(order.Uint32(tiff[4:]))
+	if ifd < 0 || ifd+2 > len(tiff) {
+		return 1
+	}
+
+	entries := int(order.Uint16(tiff[ifd:]))
+	for i := 0; i < entries; i++ {
+		entry := ifd + 2 + i*12
+		if entry+12 > len(tiff) {
+			return 1
+		}
+
+		if order.Uint16(tiff[entry:]) == exifOrientationTag {
+			orientation := int(order.Uint16(tiff[entry+8:]))
+			if orientation < 1 || orientation > 8 {
+				return 1
+			}
+
+			return orientation
+		}
+	}
+
+	return 1
+}
@@ -0,0 +1,418 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	exifMarker = []byte("Exif\x00\x00")
+	xmpMarker  = []byte("http://ns.adobe.com/xap/1.0/\x00")
+	iptcMarker = []byte("Photoshop 3.0\x00")
+	iccMarker  = []byte("ICC_PROFILE\x00")
+	secret     = []byte("GPS 50.0755N 14.4378E")
+)
+
+func gradient(width, height int, alpha uint8) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.SetNRGBA(x, y, color.NRGBA{R: uint8(x * 255 / width), G: uint8(y * 255 / height), B: 128, A: alpha})
+		}
+	}
+
+	return img
+}
+
+func encodeJPEG(t testing.TB, width, height int) []byte {
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, gradient(width, height, 255), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+func encodePNG(t testing.TB, width, height int, alpha uint8) []byte {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, gradient(width, height, alpha)); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+func encodeGIF(t testing.TB, width, height int) []byte {
+	paletted := image.NewPaletted(image.Rect(0, 0, width, height), color.Palette{color.Black, color.White})
+
+	var buf bytes.Buffer
+	err := gif.EncodeAll(&buf, &gif.GIF{
+		Image:     []*image.Paletted{paletted, paletted},
+		Delay:     []int{10, 10},
+		LoopCount: 0,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+func jpegSegmentOf(marker byte, payload ...[]byte) []byte {
+	body := bytes.Join(payload, nil)
+
+	segment := []byte{0xFF, marker, 0, 0}
+	binary.BigEndian.PutUint16(segment[2:], uint16(len(body)+2))
+
+	return append(segment, body...)
+}
+
+// withJPEGSegments inserts the segments right after the start of image.
+func withJPEGSegments(data []byte, segments ...[]byte) []byte {
+	out := append([]byte{}, data[:2]...)
+	for _, segment := range segments {
+		out = append(out, segment...)
+	}
+
+	return append(out, data[2:]...)
+}
+
+// exifWithOrientation builds an APP1 EXIF payload holding only the orientation tag.
+func exifWithOrientation(order binary.ByteOrder, orientation uint16) []byte {
+	tiff := make([]byte, 26)
+	if order == binary.LittleEndian {
+		copy(tiff, "II")
+	} else {
+		copy(tiff, "MM")
+	}
+	order.PutUint16(tiff[2:], 42)
+	order.PutUint32(tiff[4:], 8)
+	order.PutUint16(tiff[8:], 1)
+	order.PutUint16(tiff[10:], exifOrientationTag)
+	order.PutUint16(tiff[12:], 3)
+	order.PutUint32(tiff[14:], 1)
+	order.PutUint16(tiff[18:], orientation)
+
+	return append(append([]byte{}, exifMarker...), tiff...)
+}
+
+func pngChunkOf(chunkType string, data []byte) []byte {
+	chunk := make([]byte, 8, 12+len(data))
+	binary.BigEndian.PutUint32(chunk, uint32(len(data)))
+	copy(chunk[4:], chunkType)
+	chunk = append(chunk, data...)
+
+	return binary.BigEndian.AppendUint32(chunk, crc32.ChecksumIEEE(chunk[4:]))
+}
+
+// withPNGChunks inserts the chunks right after IHDR.
+func withPNGChunks(data []byte, chunks ...[]byte) []byte {
+	const ihdrEnd = 8 + 12 + 13
+
+	out := append([]byte{}, data[:ihdrEnd]...)
+	for _, chunk := range chunks {
+		out = append(out, chunk...)
+	}
+
+	return append(out, data[ihdrEnd:]...)
+}
+
+func gifExtensionOf(label byte, blocks ...[]byte) []byte {
+	out := []byte{gifExtension, label}
+	for _, block := range blocks {
+		out = append(out, byte(len(block)))
+		out = append(out, block...)
+	}
+
+	return append(out, 0)
+}
+
+// withGIFExtensions inserts the extensions before the first block after the header.
+func withGIFExtensions(data []byte, extensions ...[]byte) []byte {
+	pos := 13
+	if data[10]&0x80 != 0 {
+		pos += 3 << ((data[10] & 0x07) + 1)
+	}
+
+	out := append([]byte{}, data[:pos]...)
+	for _, extension := range extensions {
+		out = append(out, extension...)
+	}
+
+	return append(out, data[pos:]...)
+}
+
+func webpChunkOf(fourCC string, data []byte) []byte {
+	chunk := make([]byte, 8, 9+len(data))
+	copy(chunk, fourCC)
+	binary.LittleEndian.PutUint32(chunk[4:], uint32(len(data)))
+	chunk = append(chunk, data...)
+	if len(data)%2 == 1 {
+		chunk = append(chunk, 0)
+	}
+
+	return chunk
+}
+
+func webpOf(chunks ...[]byte) []byte {
+	body := append([]byte("WEBP"), bytes.Join(chunks, nil)...)
+
+	out := []byte("RIFF\x00\x00\x00\x00")
+	binary.LittleEndian.PutUint32(out[4:], uint32(len(body)))
+
+	return append(out, body...)
+}
+
+func assertDecodes(t *testing.T, data []byte, width, height int) {
+	t.Helper()
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("stripped image does not decode: %v", err)
+	}
+	if cfg.Width != width || cfg.Height != height {
+		t.Fatalf("stripped image is %dx%d, want %dx%d", cfg.Width, cfg.Height, width, height)
+	}
+}
+
+func TestStripMetadata(t *testing.T) {
+	var (
+		jpegData = encodeJPEG(t, 24, 16)
+		pngData  = encodePNG(t, 24, 16, 255)
+		gifData  = encodeGIF(t, 24, 16)
+	)
+
+	tests := []struct {
+		name        string
+		contentType string
+		data        []byte
+		removed     [][]byte
+		kept        [][]byte
+		decodes     bool
+	}{
+		{
+			name:        "jpeg",
+			contentType: "image/jpeg",
+			data: withJPEGSegments(jpegData,
+				jpegSegmentOf(jpegAPP1, exifWithOrientation(binary.BigEndian, 1), secret),
+				jpegSegmentOf(jpegAPP1, xmpMarker, secret),
+				jpegSegmentOf(0xE2, iccMarker, []byte("profile")),
+				jpegSegmentOf(jpegAPPD, iptcMarker, secret),
+				jpegSegmentOf(jpegCOM, secret),
+			),
+			removed: [][]byte{exifMarker, xmpMarker, iptcMarker, secret},
+			kept:    [][]byte{iccMarker, []byte("profile")},
+			decodes: true,
+		},
+		{
+			name:        "jpeg with fill bytes and stuffed zeros between segments",
+			contentType: "image/jpeg",
+			data: withJPEGSegments(jpegData,
+				[]byte{0xFF, 0xFF, 0xFF},
+				jpegSegmentOf(jpegCOM, secret),
+				[]byte{0xFF, 0x00},
+				jpegSegmentOf(jpegAPP1, exifWithOrientation(binary.LittleEndian, 1), secret),
+			),
+			removed: [][]byte{exifMarker, secret},
+			decodes: true,
+		},
+		{
+			name:        "png",
+			contentType: "image/png",
+			data: withPNGChunks(pngData,
+				pngChunkOf("eXIf", append(exifWithOrientation(binary.BigEndian, 1)[6:], secret...)),
+				pngChunkOf("tEXt", append([]byte("Comment\x00"), secret...)),
+				pngChunkOf("zTXt", append([]byte("Author\x00\x00"), secret...)),
+				pngChunkOf("iTXt", append([]byte("XML:com.adobe.xmp\x00\x00\x00\x00\x00"), secret...)),
+				pngChunkOf("tIME", []byte{0x07, 0xE8, 1, 2, 3, 4, 5}),
+				pngChunkOf("gAMA", []byte{0, 0, 0xB1, 0x8F}),
+			),
+			removed: [][]byte{[]byte("eXIf"), []byte("tEXt"), []byte("zTXt"), []byte("iTXt"), []byte("tIME"), secret},
+			kept:    [][]byte{[]byte("gAMA"), []byte("IDAT")},
+			decodes: true,
+		},
+		{
+			name:        "gif",
+			contentType: "image/gif",
+			data: withGIFExtensions(gifData,
+				gifExtensionOf(gifCommentLabel, secret),
+				gifExtensionOf(gifApplicationLabel, []byte("XMP DataXMP"), secret),
+			),
+			removed: [][]byte{[]byte("XMP DataXMP"), secret},
+			kept:    [][]byte{[]byte("NETSCAPE2.0")},
+			decodes: true,
+		},
+		{
+			name:        "webp",
+			contentType: "image/webp",
+			data: webpOf(
+				webpChunkOf("VP8X", []byte{webpEXIFFlag | webpXMPFlag | 0x20, 0, 0, 0, 23, 0, 0, 15, 0, 0}),
+				webpChunkOf("ICCP", []byte("profile")),
+				webpChunkOf("VP8L", []byte("pixels")),
+				webpChunkOf("EXIF", append([]byte("II*\x00"), secret...)),
+				webpChunkOf("XMP ", secret),
+			),
+			removed: [][]byte{[]byte("EXIF"), []byte("XMP "), secret},
+			kept:    [][]byte{[]byte("ICCP"), []byte("profile"), []byte("VP8L"), []byte("pixels")},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stripped, err := StripMetadata(tt.data, tt.contentType)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, removed := range tt.removed {
+				if bytes.Contains(stripped, removed) {
+					t.Errorf("%q was not stripped", removed)
+				}
+			}
+			for _, kept := range tt.kept {
+				if !bytes.Contains(stripped, kept) {
+					t.Errorf("%q was stripped", kept)
+				}
+			}
+
+			if tt.decodes {
+				assertDecodes(t, stripped, 24, 16)
+			}
+
+			again, err := StripMetadata(stripped, tt.contentType)
+			if err != nil || !bytes.Equal(again, stripped) {
+				t.Errorf("stripping is not idempotent: %v", err)
+			}
+		})
+	}
+}
+
+func TestStripWebPHeader(t *testing.T) {
+	stripped, err := stripWebP(webpOf(
+		webpChunkOf("VP8X", []byte{webpEXIFFlag | webpXMPFlag | 0x20, 0, 0, 0, 0, 0, 0, 0, 0, 0}),
+		webpChunkOf("VP8L", []byte("pixels")),
+		webpChunkOf("EXIF", []byte("odd")),
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if size := binary.LittleEndian.Uint32(stripped[4:]); int(size) != len(stripped)-8 {
+		t.Errorf("RIFF size is %d, want %d", size, len(stripped)-8)
+	}
+	if flags := stripped[20]; flags != 0x20 {
+		t.Errorf("VP8X flags are %#x, want only the ICC flag", flags)
+	}
+}
+
+func TestStripMetadataMalformed(t *testing.T) {
+	var (
+		jpegData = encodeJPEG(t, 8, 8)
+		pngData  = encodePNG(t, 8, 8, 255)
+		gifData  = encodeGIF(t, 8, 8)
+	)
+
+	tests := []struct {
+		name        string
+		contentType string
+		data        []byte
+	}{
+		{"jpeg without start of image", "image/jpeg", []byte("not a jpeg at all")},
+		{"jpeg truncated in the header", "image/jpeg", jpegData[:20]},
+		{"jpeg segment shorter than its length", "image/jpeg", []byte{0xFF, 0xD8, 0xFF, 0xE0, 0x00, 0x10, 0x00}},
+		{"jpeg segment length below two", "image/jpeg", []byte{0xFF, 0xD8, 0xFF, 0xE0, 0x00, 0x01, 0xFF, 0xD9}},
+		{"jpeg stuffed zeros without segments", "image/jpeg", []byte{0xFF, 0xD8, 0xFF, 0x00, 0x00, 0x00, 0x00, 0x00}},
+		{"png without signature", "image/png", []byte("GIF89a")},
+		{"png truncated chunk", "image/png", pngData[:40]},
+		{"png huge chunk length", "image/png", append(append([]byte{}, pngSignature...), 0xFF, 0xFF, 0xFF, 0xFF, 'I', 'H', 'D', 'R')},
+		{"gif too short", "image/gif", []byte("GIF89a")},
+		{"gif without trailer", "image/gif", gifData[:len(gifData)-1]},
+		{"gif truncated sub-blocks", "image/gif", withGIFExtensions(gifData[:13], []byte{gifExtension, gifCommentLabel, 0x40, 'x'})},
+		{"gif unknown block", "image/gif", withGIFExtensions(gifData[:13], []byte{0x42})},
+		{"webp without header", "image/webp", []byte("RIFF\x00\x00\x00\x00WEBQ")},
+		{"webp chunk past the end", "image/webp", append(webpOf(), 'V', 'P', '8', 'L', 0xFF, 0, 0, 0)},
+		{"webp truncated chunk header", "image/webp", append(webpOf(), 'V', 'P')},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := StripMetadata(tt.data, tt.contentType)
+			if !errors.Is(err, MalformedImageErr) {
+				t.Fatalf("got %v, want MalformedImageErr", err)
+			}
+		})
+	}
+
+	_, err := StripMetadata(jpegData, "image/bmp")
+	if !errors.Is(err, UnsupportedFormatErr) {
+		t.Fatalf("got %v, want UnsupportedFormatErr", err)
+	}
+}
+
+func TestJPEGOrientation(t *testing.T) {
+	jpegData := encodeJPEG(t, 8, 8)
+
+	tests := []struct {
+		name string
+		data []byte
+		want int
+	}{
+		{"no exif", jpegData, 1},
+		{"little endian", withJPEGSegments(jpegData, jpegSegmentOf(jpegAPP1, exifWithOrientation(binary.LittleEndian, 6))), 6},
+		{"big endian", withJPEGSegments(jpegData, jpegSegmentOf(jpegAPP1, exifWithOrientation(binary.BigEndian, 8))), 8},
+		{"after other segments", withJPEGSegments(jpegData,
+			jpegSegmentOf(0xE0, []byte("JFIF\x00")),
+			[]byte{0xFF, 0x00},
+			jpegSegmentOf(jpegAPP1, exifWithOrientation(binary.BigEndian, 3)),
+		), 3},
+		{"xmp only", withJPEGSegments(jpegData, jpegSegmentOf(jpegAPP1, xmpMarker)), 1},
+		{"out of range", withJPEGSegments(jpegData, jpegSegmentOf(jpegAPP1, exifWithOrientation(binary.BigEndian, 9))), 1},
+		{"truncated tiff", withJPEGSegments(jpegData, jpegSegmentOf(jpegAPP1, exifWithOrientation(binary.BigEndian, 6)[:16])), 1},
+		{"ifd offset past the end", withJPEGSegments(jpegData, jpegSegmentOf(jpegAPP1, exifMarker, []byte("MM\x00\x2A\xFF\xFF\xFF\xF0"))), 1},
+		{"unknown byte order", withJPEGSegments(jpegData, jpegSegmentOf(jpegAPP1, exifMarker, []byte("XX\x00\x2A\x00\x00\x00\x08"))), 1},
+		{"segment length below two", []byte{0xFF, 0xD8, 0xFF, 0x00, 0x00, 0x00, 0xFF, 0xE1, 0x00, 0x00}, 1},
+		{"truncated", []byte{0xFF, 0xD8, 0xFF}, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := jpegOrientation(tt.data); got != tt.want {
+				t.Fatalf("got %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func FuzzStripMetadata(f *testing.F) {
+	jpegData := encodeJPEG(f, 8, 8)
+
+	f.Add(jpegData, "image/jpeg")
+	f.Add(withJPEGSegments(jpegData, jpegSegmentOf(jpegAPP1, exifWithOrientation(binary.LittleEndian, 6))), "image/jpeg")
+	f.Add([]byte{0xFF, 0xD8, 0xFF, 0x00, 0x00, 0x00, 0x00, 0x00}, "image/jpeg")
+	f.Add(encodePNG(f, 8, 8, 128), "image/png")
+	f.Add(encodeGIF(f, 8, 8), "image/gif")
+	f.Add(webpOf(webpChunkOf("VP8X", make([]byte, 10)), webpChunkOf("EXIF", secret)), "image/webp")
+
+	f.Fuzz(func(t *testing.T, data []byte, contentType string) {
+		_ = jpegOrientation(data)
+
+		stripped, err := StripMetadata(data, contentType)
+		if err != nil {
+			return
+		}
+
+		again, err := StripMetadata(stripped, contentType)
+		if err != nil || !bytes.Equal(again, stripped) {
+			t.Fatalf("stripping is not idempotent: %v", err)
+		}
+	})
+}
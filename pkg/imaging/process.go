@@ -0,0 +1,241 @@
+package imaging
+
+import (
+	"bytes"
+	"github.com/pkg/errors"
+	"image"
+	"image/color"
+	_ "image/gif"
+	"image/jpeg"
+	"image/png"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+const (
+	// maxPixels protects against decompression bombs: a small file may declare huge dimensions.
+	maxPixels = 50_000_000
+
+	blurhashSize        = 32
+	blurhashXComponents = 4
+	blurhashYComponents = 3
+
+	fullQuality      = 90
+	thumbnailQuality = 82
+
+	// FullVariant is the whole image with its metadata removed.
+	FullVariant = "full"
+)
+
+var (
+	UnsupportedFormatErr = errors.New("unsupported image format")
+	ImageTooLargeErr     = errors.New("image dimensions are too large")
+)
+
+// Size is a thumbnail bounded by MaxDimension on its longest side.
+type Size struct {
+	Name         string
+	MaxDimension int
+}
+
+type Variant struct {
+	Name        string
+	Data        []byte
+	ContentType string
+	Width       int
+	Height      int
+}
+
+type Result struct {
+	Width    int
+	Height   int
+	Blurhash string
+	Variants []Variant
+}
+
+// Process decodes the image, produces the full variant without metadata and a
+// thumbnail for every size. Dimensions are reported as displayed, i.e. after the EXIF
+// orientation is applied. Only the first frame of animated images is used for thumbnails.
+func Process(data []byte, contentType string, sizes []Size) (*Result, error) {
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, errors.Wrapf(UnsupportedFormatErr, "imaging.Process: %v", err)
+	}
+
+	if cfg.Width*cfg.Height > maxPixels {
+		return nil, errors.Wrapf(ImageTooLargeErr, "imaging.Process: %dx%d", cfg.Width, cfg.Height)
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, errors.Wrapf(MalformedImageErr, "imaging.Process: %v", err)
+	}
+
+	orientation := 1
+	if contentType == "image/jpeg" {
+		orientation = jpegOrientation(data)
+	}
+
+	full, err := fullVariant(data, contentType, img, orientation)
+	if err != nil {
+		return nil, errors.Wrap(err, "imaging.Process")
+	}
+
+	result := &Result{
+		Width:    full.Width,
+		Height:   full.Height,
+		Variants: []Variant{*full},
+	}
+
+	opaque := isOpaque(img)
+	for _, size := range sizes {
+		thumbnail := orient(scale(img, size.MaxDimension), orientation)
+
+		variant, err := encode(size.Name, thumbnail, opaque, thumbnailQuality)
+		if err != nil {
+			return nil, errors.Wrap(err, "imaging.Process")
+		}
+
+		result.Variants = append(result.Variants, *variant)
+	}
+
+	result.Blurhash = Blurhash(orient(scale(img, blurhashSize), orientation), blurhashXComponents, blurhashYComponents)
+
+	return result, nil
+}
+
+// fullVariant keeps the original encoding when possible. A rotated JPEG has to be
+// re-encoded: once its EXIF is gone, nothing would tell viewers to rotate it.
+func fullVariant(data []byte, contentType string, img image.Image, orientation int) (*Variant, error) {
+	if orientation != 1 {
+		return encode(FullVariant, orient(toNRGBA(img), orientation), true, fullQuality)
+	}
+
+	stripped, err := StripMetadata(data, contentType)
+	if err != nil {
+		return nil, err
+	}
+
+	bounds := img.Bounds()
+
+	return &Variant{
+		Name:        FullVariant,
+		Data:        stripped,
+		ContentType: contentType,
+		Width:       bounds.Dx(),
+		Height:      bounds.Dy(),
+	}, nil
+}
+
+// encode writes opaque images as JPEG and images with transparency as PNG.
+func encode(name string, img *image.NRGBA, opaque bool, quality int) (*Variant, error) {
+	var (
+		buf         bytes.Buffer
+		contentType string
+		err         error
+	)
+
+	if opaque {
+		contentType = "image/jpeg"
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: quality})
+	} else {
+		contentType = "image/png"
+		err = png.Encode(&buf, img)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	bounds := img.Bounds()
+
+	return &Variant{
+		Name:        name,
+		Data:        buf.Bytes(),
+		ContentType: contentType,
+		Width:       bounds.Dx(),
+		Height:      bounds.Dy(),
+	}, nil
+}
+
+// scale fits the image into maxDimension on its longest side. Images are never upscaled.
+func scale(img image.Image, maxDimension int) *image.NRGBA {
+	var (
+		bounds = img.Bounds()
+		width  = bounds.Dx()
+		height = bounds.Dy()
+	)
+
+	if longest := max(width, height); longest > maxDimension {
+		width = max(1, width*maxDimension/longest)
+		height = max(1, height*maxDimension/longest)
+	}
+
+	dst := image.NewNRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, bounds, draw.Src, nil)
+
+	return dst
+}
+
+func toNRGBA(img image.Image) *image.NRGBA {
+	bounds := img.Bounds()
+	dst := image.NewNRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(dst, dst.Bounds(), img, bounds.Min, draw.Src)
+
+	return dst
+}
+
+// orient applies an EXIF orientation, see the Orientation tag of the EXIF specification.
+func orient(img *image.NRGBA, orientation int) *image.NRGBA {
+	if orientation <= 1 || orientation > 8 {
+		return img
+	}
+
+	var (
+		width  = img.Bounds().Dx()
+		height = img.Bounds().Dy()
+		dst    *image.NRGBA
+	)
+
+	if orientation >= 5 {
+		dst = image.NewNRGBA(image.Rect(0, 0, height, width))
+	} else {
+		dst = image.NewNRGBA(image.Rect(0, 0, width, height))
+	}
+
+	bounds := dst.Bounds()
+	for y := 0; y < bounds.Dy(); y++ {
+		for x := 0; x < bounds.Dx(); x++ {
+			var sx, sy int
+
+			switch orientation {
+			case 2:
+				sx, sy = width-1-x, y
+			case 3:
+				sx, sy = width-1-x, height-1-y
+			case 4:
+				sx, sy = x, height-1-y
+			case 5:
+				sx, sy = y, x
+			case 6:
+				sx, sy = y, height-1-x
+			case 7:
+				sx, sy = width-1-y, height-1-x
+			case 8:
+				sx, sy = width-1-y, x
+			}
+
+			dst.SetNRGBA(x, y, img.NRGBAAt(sx, sy))
+		}
+	}
+
+	return dst
+}
+
+func isOpaque(img image.Image) bool {
+	if o, ok := img.(interface{ Opaque() bool }); ok {
+		return o.Opaque()
+	}
+
+	return img.ColorModel() == color.GrayModel || img.ColorModel() == color.YCbCrModel
+}
@@ -0,0 +1,221 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+var testSizes = []Size{
+	{Name: "small", MaxDimension: 10},
+	{Name: "large", MaxDimension: 1000},
+}
+
+func variantOf(t *testing.T, result *Result, name string) Variant {
+	t.Helper()
+
+	for _, variant := range result.Variants {
+		if variant.Name == name {
+			return variant
+		}
+	}
+	t.Fatalf("no %q variant", name)
+
+	return Variant{}
+}
+
+func assertVariant(t *testing.T, variant Variant, contentType string, width, height int) {
+	t.Helper()
+
+	if variant.ContentType != contentType {
+		t.Errorf("%s: content type is %s, want %s", variant.Name, variant.ContentType, contentType)
+	}
+	if variant.Width != width || variant.Height != height {
+		t.Errorf("%s: reported %dx%d, want %dx%d", variant.Name, variant.Width, variant.Height, width, height)
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(variant.Data))
+	if err != nil {
+		t.Fatalf("%s: does not decode: %v", variant.Name, err)
+	}
+	if cfg.Width != width || cfg.Height != height {
+		t.Errorf("%s: encoded %dx%d, want %dx%d", variant.Name, cfg.Width, cfg.Height, width, height)
+	}
+}
+
+func TestProcess(t *testing.T) {
+	jpegData := encodeJPEG(t, 40, 20)
+
+	tests := []struct {
+		name          string
+		contentType   string
+		data          []byte
+		width, height int
+		fullType      string
+		thumbnailType string
+		small         [2]int
+	}{
+		{
+			name:          "jpeg",
+			contentType:   "image/jpeg",
+			data:          withJPEGSegments(jpegData, jpegSegmentOf(jpegCOM, secret)),
+			width:         40,
+			height:        20,
+			fullType:      "image/jpeg",
+			thumbnailType: "image/jpeg",
+			small:         [2]int{10, 5},
+		},
+		{
+			name:          "rotated jpeg",
+			contentType:   "image/jpeg",
+			data:          withJPEGSegments(jpegData, jpegSegmentOf(jpegAPP1, exifWithOrientation(binary.LittleEndian, 6), secret)),
+			width:         20,
+			height:        40,
+			fullType:      "image/jpeg",
+			thumbnailType: "image/jpeg",
+			small:         [2]int{5, 10},
+		},
+		{
+			name:          "opaque png",
+			contentType:   "image/png",
+			data:          encodePNG(t, 40, 20, 255),
+			width:         40,
+			height:        20,
+			fullType:      "image/png",
+			thumbnailType: "image/jpeg",
+			small:         [2]int{10, 5},
+		},
+		{
+			name:          "transparent png",
+			contentType:   "image/png",
+			data:          encodePNG(t, 20, 40, 128),
+			width:         20,
+			height:        40,
+			fullType:      "image/png",
+			thumbnailType: "image/png",
+			small:         [2]int{5, 10},
+		},
+		{
+			name:          "gif",
+			contentType:   "image/gif",
+			data:          encodeGIF(t, 40, 20),
+			width:         40,
+			height:        20,
+			fullType:      "image/gif",
+			thumbnailType: "image/jpeg",
+			small:         [2]int{10, 5},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Process(tt.data, tt.contentType, testSizes)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if result.Width != tt.width || result.Height != tt.height {
+				t.Errorf("result is %dx%d, want %dx%d", result.Width, result.Height, tt.width, tt.height)
+			}
+			if len(result.Blurhash) != 28 {
+				t.Errorf("blurhash %q has %d characters, want 28", result.Blurhash, len(result.Blurhash))
+			}
+			if len(result.Variants) != len(testSizes)+1 {
+				t.Fatalf("got %d variants, want %d", len(result.Variants), len(testSizes)+1)
+			}
+
+			full := variantOf(t, result, FullVariant)
+			assertVariant(t, full, tt.fullType, tt.width, tt.height)
+			if bytes.Contains(full.Data, exifMarker) || bytes.Contains(full.Data, secret) {
+				t.Error("full variant keeps the metadata")
+			}
+
+			assertVariant(t, variantOf(t, result, "small"), tt.thumbnailType, tt.small[0], tt.small[1])
+			// Images are never upscaled.
+			assertVariant(t, variantOf(t, result, "large"), tt.thumbnailType, tt.width, tt.height)
+		})
+	}
+}
+
+func TestProcessRejects(t *testing.T) {
+	ihdr := make([]byte, 13)
+	binary.BigEndian.PutUint32(ihdr, 20000)
+	binary.BigEndian.PutUint32(ihdr[4:], 20000)
+	ihdr[8] = 8
+	ihdr[9] = 2
+
+	chunk := make([]byte, 8, 25)
+	binary.BigEndian.PutUint32(chunk, uint32(len(ihdr)))
+	copy(chunk[4:], "IHDR")
+	chunk = append(chunk, ihdr...)
+	chunk = binary.BigEndian.AppendUint32(chunk, crc32.ChecksumIEEE(chunk[4:]))
+	bomb := append(append([]byte{}, pngSignature...), chunk...)
+
+	// Valid headers, so only decoding the pixels fails.
+	jpegData := encodeJPEG(t, 200, 200)
+	pngData := encodePNG(t, 200, 200, 255)
+
+	tests := []struct {
+		name        string
+		contentType string
+		data        []byte
+		err         error
+	}{
+		{"garbage", "image/jpeg", []byte("definitely not an image"), UnsupportedFormatErr},
+		{"crafted jpeg", "image/jpeg", []byte{0xFF, 0xD8, 0xFF, 0x00, 0x00, 0x00, 0x00, 0x00}, UnsupportedFormatErr},
+		{"decompression bomb", "image/png", bomb, ImageTooLargeErr},
+		{"truncated jpeg", "image/jpeg", jpegData[:len(jpegData)*3/4], MalformedImageErr},
+		{"truncated png", "image/png", pngData[:len(pngData)*3/4], MalformedImageErr},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Process(tt.data, tt.contentType, testSizes)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("got %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestOrient(t *testing.T) {
+	// A 2x1 image, red on the left and blue on the right.
+	img := image.NewNRGBA(image.Rect(0, 0, 2, 1))
+	red := color.NRGBA{R: 255, A: 255}
+	blue := color.NRGBA{B: 255, A: 255}
+	img.SetNRGBA(0, 0, red)
+	img.SetNRGBA(1, 0, blue)
+
+	tests := []struct {
+		orientation   int
+		width, height int
+		redAt         image.Point
+	}{
+		{1, 2, 1, image.Pt(0, 0)},
+		{2, 2, 1, image.Pt(1, 0)},
+		{3, 2, 1, image.Pt(1, 0)},
+		{4, 2, 1, image.Pt(0, 0)},
+		{5, 1, 2, image.Pt(0, 0)},
+		{6, 1, 2, image.Pt(0, 0)},
+		{7, 1, 2, image.Pt(0, 1)},
+		{8, 1, 2, image.Pt(0, 1)},
+	}
+
+	for _, tt := range tests {
+		oriented := orient(img, tt.orientation)
+
+		bounds := oriented.Bounds()
+		if bounds.Dx() != tt.width || bounds.Dy() != tt.height {
+			t.Errorf("orientation %d: got %dx%d, want %dx%d", tt.orientation, bounds.Dx(), bounds.Dy(), tt.width, tt.height)
+			continue
+		}
+		if got := oriented.NRGBAAt(tt.redAt.X, tt.redAt.Y); got != red {
+			t.Errorf("orientation %d: red pixel is not at %v", tt.orientation, tt.redAt)
+		}
+	}
+}
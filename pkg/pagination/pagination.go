@@ -0,0 +1,125 @@
+// Package pagination implements keyset pagination over items ordered by
+// (created_at, id). Clients get an opaque cursor pointing after the last item
+// of a page and pass it back to fetch the next one.
+package pagination
+
+import (
+	"encoding/base64"
+	"errors"
+	"github.com/google/uuid"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultLimit = 20
+	MaxLimit     = 100
+)
+
+var (
+	InvalidCursorErr = errors.New("invalid page cursor")
+	InvalidLimitErr  = errors.New("invalid page limit")
+)
+
+// Cursor points at the last item of a page.
+type Cursor struct {
+	CreatedAt time.Time
+	ID        string
+}
+
+// Page is the response envelope of list endpoints. NextCursor is empty on the last page.
+type Page[T any] struct {
+	Items      []T    `json:"items"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// Params are the decoded page request. A nil Cursor starts from the first item.
+type Params struct {
+	Cursor *Cursor
+	Limit  int
+}
+
+// Fetch is the number of items to query: one more than Limit, which tells whether there is a next page.
+func (p Params) Fetch() int {
+	return p.Limit + 1
+}
+
+// Parse decodes the cursor and limit query parameters. Both are optional.
+func Parse(cursor, limit string) (Params, error) {
+	var (
+		p   = Params{}
+		err error
+	)
+
+	p.Limit, err = ParseLimit(limit, DefaultLimit, MaxLimit)
+	if err != nil {
+		return Params{}, err
+	}
+
+	if cursor != "" {
+		p.Cursor, err = Decode(cursor)
+		if err != nil {
+			return Params{}, err
+		}
+	}
+
+	return p, nil
+}
+
+// ParseLimit parses a page size, falling back to def when it is empty and capping it at max.
+func ParseLimit(limit string, def, max int) (int, error) {
+	if limit == "" {
+		return def, nil
+	}
+
+	l, err := strconv.Atoi(limit)
+	if err != nil || l <= 0 {
+		return 0, InvalidLimitErr
+	}
+
+	return min(l, max), nil
+}
+
+func Encode(c Cursor) string {
+	raw := strconv.FormatInt(c.CreatedAt.UnixMicro(), 10) + "|" + c.ID
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func Decode(s string) (*Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, InvalidCursorErr
+	}
+
+	micros, id, found := strings.Cut(string(raw), "|")
+	if !found || id == "" {
+		return nil, InvalidCursorErr
+	}
+
+	createdAt, err := strconv.ParseInt(micros, 10, 64)
+	if err != nil {
+		return nil, InvalidCursorErr
+	}
+
+	// The id ends up in a uuid query parameter, which must not fail with a database error.
+	parsed, err := uuid.Parse(id)
+	if err != nil {
+		return nil, InvalidCursorErr
+	}
+
+	return &Cursor{CreatedAt: time.UnixMicro(createdAt).UTC(), ID: parsed.String()}, nil
+}
+
+// NewPage wraps items fetched with Params.Fetch: the extra item only signals that
+// there is a next page, which starts after the last returned item.
+func NewPage[T any](items []T, p Params, cursorOf func(T) Cursor) *Page[T] {
+	page := &Page[T]{Items: items}
+
+	if len(items) > p.Limit {
+		page.Items = items[:p.Limit]
+		page.NextCursor = Encode(cursorOf(page.Items[p.Limit-1]))
+	}
+
+	return page
+}
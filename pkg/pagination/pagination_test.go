@@ -0,0 +1,169 @@
+package pagination
+
+import (
+	"encoding/base64"
+	"errors"
+	"testing"
+	"time"
+)
+
+const testID = "0b9d6f5e-3c4a-4b8e-9f1a-2d7c5e8b1a3f"
+
+func TestEncodeDecode(t *testing.T) {
+	cursor := Cursor{CreatedAt: time.Date(2024, 5, 17, 12, 30, 45, 123456789, time.UTC), ID: testID}
+
+	decoded, err := Decode(Encode(cursor))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Postgres keeps microseconds, so the cursor does too.
+	want := Cursor{CreatedAt: cursor.CreatedAt.Truncate(time.Microsecond), ID: testID}
+	if *decoded != want {
+		t.Fatalf("got %+v, want %+v", *decoded, want)
+	}
+}
+
+func TestDecodeNormalisesID(t *testing.T) {
+	raw := "1715949045123456|{0B9D6F5E-3C4A-4B8E-9F1A-2D7C5E8B1A3F}"
+
+	decoded, err := Decode(base64.RawURLEncoding.EncodeToString([]byte(raw)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decoded.ID != testID {
+		t.Fatalf("got id %q, want %q", decoded.ID, testID)
+	}
+}
+
+func TestDecodeInvalid(t *testing.T) {
+	encode := func(raw string) string {
+		return base64.RawURLEncoding.EncodeToString([]byte(raw))
+	}
+
+	tests := []struct {
+		name   string
+		cursor string
+	}{
+		{"not base64", "!!!"},
+		{"padded base64", base64.URLEncoding.EncodeToString([]byte("1|" + testID))},
+		{"no separator", encode("1715949045123456")},
+		{"empty id", encode("1715949045123456|")},
+		{"id is not a uuid", encode("1715949045123456|1; DROP TABLE posts")},
+		{"time is not a number", encode("yesterday|" + testID)},
+		{"empty time", encode("|" + testID)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Decode(tt.cursor)
+			if !errors.Is(err, InvalidCursorErr) {
+				t.Fatalf("got %v, want InvalidCursorErr", err)
+			}
+		})
+	}
+}
+
+func TestParseLimit(t *testing.T) {
+	tests := []struct {
+		limit string
+		want  int
+		err   error
+	}{
+		{"", DefaultLimit, nil},
+		{"1", 1, nil},
+		{"50", 50, nil},
+		{"100", MaxLimit, nil},
+		{"1000", MaxLimit, nil},
+		{"0", 0, InvalidLimitErr},
+		{"-5", 0, InvalidLimitErr},
+		{"ten", 0, InvalidLimitErr},
+		{"1.5", 0, InvalidLimitErr},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseLimit(tt.limit, DefaultLimit, MaxLimit)
+		if !errors.Is(err, tt.err) || got != tt.want {
+			t.Errorf("ParseLimit(%q) = %d, %v; want %d, %v", tt.limit, got, err, tt.want, tt.err)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	p, err := Parse("", "")
+	if err != nil || p.Cursor != nil || p.Limit != DefaultLimit || p.Fetch() != DefaultLimit+1 {
+		t.Fatalf("got %+v, %v; want the first page of the default size", p, err)
+	}
+
+	cursor := Encode(Cursor{CreatedAt: time.Now(), ID: testID})
+	p, err = Parse(cursor, "5")
+	if err != nil || p.Cursor == nil || p.Cursor.ID != testID || p.Limit != 5 {
+		t.Fatalf("got %+v, %v", p, err)
+	}
+
+	_, err = Parse("garbage", "5")
+	if !errors.Is(err, InvalidCursorErr) {
+		t.Fatalf("got %v, want InvalidCursorErr", err)
+	}
+
+	_, err = Parse(cursor, "0")
+	if !errors.Is(err, InvalidLimitErr) {
+		t.Fatalf("got %v, want InvalidLimitErr", err)
+	}
+}
+
+type item struct {
+	id        string
+	createdAt time.Time
+}
+
+func cursorOf(i item) Cursor {
+	return Cursor{CreatedAt: i.createdAt, ID: i.id}
+}
+
+func TestNewPage(t *testing.T) {
+	start := time.Date(2024, 5, 17, 0, 0, 0, 0, time.UTC)
+
+	items := make([]item, 4)
+	for i := range items {
+		items[i] = item{id: testID, createdAt: start.Add(-time.Duration(i) * time.Minute)}
+	}
+
+	tests := []struct {
+		name   string
+		items  []item
+		limit  int
+		length int
+		next   *Cursor
+	}{
+		{"empty", nil, 3, 0, nil},
+		{"shorter than the limit", items[:2], 3, 2, nil},
+		{"exactly the limit", items[:3], 3, 3, nil},
+		{"one more than the limit", items, 3, 3, &Cursor{CreatedAt: items[2].createdAt, ID: testID}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page := NewPage(tt.items, Params{Limit: tt.limit}, cursorOf)
+
+			if len(page.Items) != tt.length {
+				t.Fatalf("got %d items, want %d", len(page.Items), tt.length)
+			}
+
+			if tt.next == nil {
+				if page.NextCursor != "" {
+					t.Fatalf("got next cursor %q on the last page", page.NextCursor)
+				}
+				return
+			}
+
+			next, err := Decode(page.NextCursor)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if *next != *tt.next {
+				t.Fatalf("next cursor is %+v, want %+v", *next, *tt.next)
+			}
+		})
+	}
+}
@@ -0,0 +1,291 @@
+// Package validation checks structs against declarative rules given in `validate` tags:
+//
+//	Title string `json:"title" validate:"required,max=32"`
+//
+// Rules are separated by commas:
+//
+//	required    the value is not nil, not blank and not an empty slice
+//	min=N       a string has at least N characters, a slice at least N elements
+//	max=N       a string has at most N characters, a slice at most N elements
+//	maxbytes=N  a string is at most N bytes long in UTF-8
+//	uuid        a non-empty string, or every string of a slice, is a UUID
+//	username    a non-empty string consists of letters, digits and underscores
+//
+// Rules of a pointer field apply to the value it points to; a nil pointer only fails
+// required. Fields are reported under their JSON names.
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	CodeRequired     = "required"
+	CodeTooShort     = "too_short"
+	CodeTooLong      = "too_long"
+	CodeInvalidUUID  = "invalid_uuid"
+	CodeInvalidChars = "invalid_characters"
+	CodeInvalidType  = "invalid_type"
+	CodeUnknownField = "unknown_field"
+	CodeNotAllowed   = "not_allowed"
+)
+
+// FieldError describes why a field is invalid. Limit is the bound of min, max and maxbytes rules.
+type FieldError struct {
+	Field string `json:"field"`
+	Code  string `json:"code"`
+	Limit int    `json:"limit,omitempty"`
+}
+
+// Errors lists every invalid field of a struct.
+type Errors []FieldError
+
+func (e Errors) Error() string {
+	fields := make([]string, 0, len(e))
+	for _, f := range e {
+		fields = append(fields, f.Field+": "+f.Code)
+	}
+
+	return "validation failed: " + strings.Join(fields, ", ")
+}
+
+// InvalidTagErr is returned for a validate tag with an unknown rule or a malformed
+// parameter, which is a bug in the struct rather than in the validated value.
+var InvalidTagErr = errors.New("validation: invalid tag")
+
+// rule is a parsed rule of a validate tag. Limit is the parameter of min, max and
+// maxbytes, and zero for the other rules.
+type rule struct {
+	name  string
+	limit int
+}
+
+// field is a struct field that has rules.
+type field struct {
+	index int
+	name  string
+	rules []rule
+}
+
+// fieldCache holds the fields of every struct type validated so far, so tags are parsed once.
+var fieldCache sync.Map
+
+// Validate checks the struct v points to and returns Errors if any of its fields is
+// invalid, or InvalidTagErr if its tags are malformed.
+func Validate(v any) error {
+	value := reflect.Indirect(reflect.ValueOf(v))
+	if value.Kind() != reflect.Struct {
+		return nil
+	}
+
+	fields, err := fieldsOf(value.Type())
+	if err != nil {
+		return err
+	}
+
+	var errs Errors
+
+	for _, f := range fields {
+		for _, r := range f.rules {
+			code := check(value.Field(f.index), r)
+			if code != "" {
+				errs = append(errs, FieldError{Field: f.name, Code: code, Limit: r.limit})
+				break
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+// CheckTag returns InvalidTagErr if the validate tag has an unknown rule or a malformed parameter.
+func CheckTag(tag string) error {
+	_, err := parseTag(tag)
+	return err
+}
+
+func fieldsOf(t reflect.Type) ([]field, error) {
+	if cached, ok := fieldCache.Load(t); ok {
+		return cached.([]field), nil
+	}
+
+	var fields []field
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+
+		tag := f.Tag.Get("validate")
+		if tag == "" || !f.IsExported() {
+			continue
+		}
+
+		rules, err := parseTag(tag)
+		if err != nil {
+			return nil, fmt.Errorf("%w: field %s of %s", err, f.Name, t)
+		}
+
+		fields = append(fields, field{index: i, name: fieldName(f), rules: rules})
+	}
+
+	fieldCache.Store(t, fields)
+
+	return fields, nil
+}
+
+func parseTag(tag string) ([]rule, error) {
+	var rules []rule
+
+	for _, text := range strings.Split(tag, ",") {
+		name, param, hasParam := strings.Cut(text, "=")
+
+		r := rule{name: name}
+		switch name {
+		case "min", "max", "maxbytes":
+			limit, err := strconv.Atoi(param)
+			if err != nil || limit < 0 {
+				return nil, fmt.Errorf("%w: rule %q needs a non-negative number", InvalidTagErr, text)
+			}
+			r.limit = limit
+		case "required", "uuid", "username":
+			if hasParam {
+				return nil, fmt.Errorf("%w: rule %q takes no parameter", InvalidTagErr, text)
+			}
+		default:
+			return nil, fmt.Errorf("%w: unknown rule %q", InvalidTagErr, text)
+		}
+
+		rules = append(rules, r)
+	}
+
+	return rules, nil
+}
+
+// check applies a single rule and returns the error code, if the value breaks it.
+func check(v reflect.Value, r rule) string {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			if r.name == "required" {
+				return CodeRequired
+			}
+			return ""
+		}
+
+		v = v.Elem()
+	}
+
+	switch r.name {
+	case "required":
+		if isEmpty(v) {
+			return CodeRequired
+		}
+	case "min":
+		if length(v) < r.limit {
+			return CodeTooShort
+		}
+	case "max":
+		if length(v) > r.limit {
+			return CodeTooLong
+		}
+	case "maxbytes":
+		if v.Kind() == reflect.String && len(v.String()) > r.limit {
+			return CodeTooLong
+		}
+	case "uuid":
+		for _, s := range stringsOf(v) {
+			if s != "" && !isUUID(s) {
+				return CodeInvalidUUID
+			}
+		}
+	case "username":
+		for _, s := range stringsOf(v) {
+			if strings.IndexFunc(s, isNotUsernameRune) >= 0 {
+				return CodeInvalidChars
+			}
+		}
+	}
+
+	return ""
+}
+
+func fieldName(f reflect.StructField) string {
+	name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+	if name == "" || name == "-" {
+		return f.Name
+	}
+
+	return name
+}
+
+func isEmpty(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.String:
+		return strings.TrimSpace(v.String()) == ""
+	case reflect.Slice, reflect.Map:
+		return v.Len() == 0
+	default:
+		return v.IsZero()
+	}
+}
+
+// length counts the characters of a string and the elements of a slice.
+func length(v reflect.Value) int {
+	switch v.Kind() {
+	case reflect.String:
+		return utf8.RuneCountInString(v.String())
+	case reflect.Slice, reflect.Map:
+		return v.Len()
+	default:
+		return 0
+	}
+}
+
+func stringsOf(v reflect.Value) []string {
+	switch {
+	case v.Kind() == reflect.String:
+		return []string{v.String()}
+	case v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.String:
+		result := make([]string, v.Len())
+		for i := range result {
+			result[i] = v.Index(i).String()
+		}
+		return result
+	default:
+		return nil
+	}
+}
+
+func isUUID(s string) bool {
+	if len(s) != 36 {
+		return false
+	}
+
+	for i, r := range s {
+		switch i {
+		case 8, 13, 18, 23:
+			if r != '-' {
+				return false
+			}
+		default:
+			if !unicode.Is(unicode.ASCII_Hex_Digit, r) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+func isNotUsernameRune(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_'
+}
@@ -0,0 +1,159 @@
+package validation
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testUUID = "0b9d6f5e-3c4a-4b8e-9f1a-2d7c5e8b1a3f"
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func TestRules(t *testing.T) {
+	tests := []struct {
+		name  string
+		value any
+		want  Errors
+	}{
+		{"required string", &struct {
+			Title string `json:"title" validate:"required"`
+		}{"  "}, Errors{{Field: "title", Code: CodeRequired}}},
+		{"required present", &struct {
+			Title string `json:"title" validate:"required"`
+		}{"hello"}, nil},
+		{"required slice", &struct {
+			IDs []string `json:"ids" validate:"required"`
+		}{[]string{}}, Errors{{Field: "ids", Code: CodeRequired}}},
+		{"required nil pointer", &struct {
+			Title *string `json:"title" validate:"required"`
+		}{nil}, Errors{{Field: "title", Code: CodeRequired}}},
+		{"optional nil pointer", &struct {
+			Title *string `json:"title" validate:"min=3,max=5"`
+		}{nil}, nil},
+		{"min characters", &struct {
+			Title string `json:"title" validate:"min=3"`
+		}{"éé"}, Errors{{Field: "title", Code: CodeTooShort, Limit: 3}}},
+		{"min on pointer", &struct {
+			Title *string `json:"title" validate:"min=1"`
+		}{ptr("")}, Errors{{Field: "title", Code: CodeTooShort, Limit: 1}}},
+		{"max characters", &struct {
+			Title string `json:"title" validate:"max=3"`
+		}{"éééé"}, Errors{{Field: "title", Code: CodeTooLong, Limit: 3}}},
+		{"max counts characters, not bytes", &struct {
+			Title string `json:"title" validate:"max=3"`
+		}{"ééé"}, nil},
+		{"max elements", &struct {
+			Keys []string `json:"keys" validate:"max=1"`
+		}{[]string{"a", "b"}}, Errors{{Field: "keys", Code: CodeTooLong, Limit: 1}}},
+		{"maxbytes", &struct {
+			Password string `json:"password" validate:"maxbytes=4"`
+		}{"ééé"}, Errors{{Field: "password", Code: CodeTooLong, Limit: 4}}},
+		{"maxbytes within", &struct {
+			Password string `json:"password" validate:"maxbytes=4"`
+		}{"éé"}, nil},
+		{"uuid", &struct {
+			ID string `json:"id" validate:"uuid"`
+		}{"not-a-uuid"}, Errors{{Field: "id", Code: CodeInvalidUUID}}},
+		{"uuid empty", &struct {
+			ID string `json:"id" validate:"uuid"`
+		}{""}, nil},
+		{"uuid valid", &struct {
+			ID string `json:"id" validate:"uuid"`
+		}{testUUID}, nil},
+		{"uuid slice", &struct {
+			IDs []string `json:"ids" validate:"uuid"`
+		}{[]string{testUUID, strings.Replace(testUUID, "-", "x", 1)}}, Errors{{Field: "ids", Code: CodeInvalidUUID}}},
+		{"username", &struct {
+			Username string `json:"username" validate:"username"`
+		}{"john doe"}, Errors{{Field: "username", Code: CodeInvalidChars}}},
+		{"username valid", &struct {
+			Username string `json:"username" validate:"username"`
+		}{"jöhn_2"}, nil},
+		{"first failing rule only", &struct {
+			Username string `json:"username" validate:"required,max=4,username"`
+		}{"john doe"}, Errors{{Field: "username", Code: CodeTooLong, Limit: 4}}},
+		{"every invalid field", &struct {
+			Username string `json:"username" validate:"required"`
+			Password string `json:"password" validate:"min=8"`
+		}{"", "short"}, Errors{{Field: "username", Code: CodeRequired}, {Field: "password", Code: CodeTooShort, Limit: 8}}},
+		{"go name without json tag", &struct {
+			Title string `validate:"required"`
+		}{""}, Errors{{Field: "Title", Code: CodeRequired}}},
+		{"fields without tags", &struct {
+			Title string `json:"title"`
+		}{""}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(tt.value)
+
+			var got Errors
+			if err != nil && !errors.As(err, &got) {
+				t.Fatalf("got %v, want validation errors", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateNonStruct(t *testing.T) {
+	if err := Validate(ptr("value")); err != nil {
+		t.Fatalf("got %v for a non-struct", err)
+	}
+}
+
+func TestInvalidTags(t *testing.T) {
+	tests := []struct {
+		name  string
+		value any
+	}{
+		{"unknown rule", &struct {
+			Title string `validate:"requird"`
+		}{}},
+		{"missing limit", &struct {
+			Title string `validate:"max"`
+		}{}},
+		{"limit is not a number", &struct {
+			Title string `validate:"min=three"`
+		}{}},
+		{"negative limit", &struct {
+			Title string `validate:"max=-1"`
+		}{}},
+		{"parameter of a rule without one", &struct {
+			ID string `validate:"uuid=4"`
+		}{}},
+		{"empty rule", &struct {
+			Title string `validate:"required,"`
+		}{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(tt.value)
+			if !errors.Is(err, InvalidTagErr) {
+				t.Fatalf("got %v, want InvalidTagErr", err)
+			}
+
+			// Nothing is cached for an invalid tag, so every call fails the same way.
+			if err := Validate(tt.value); !errors.Is(err, InvalidTagErr) {
+				t.Fatalf("second call: got %v, want InvalidTagErr", err)
+			}
+		})
+	}
+}
+
+func TestCheckTag(t *testing.T) {
+	if err := CheckTag("required,min=8,maxbytes=72"); err != nil {
+		t.Fatalf("valid tag: %v", err)
+	}
+	if err := CheckTag("required,maxlen=72"); !errors.Is(err, InvalidTagErr) {
+		t.Fatalf("got %v, want InvalidTagErr", err)
+	}
+}